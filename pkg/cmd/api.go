@@ -50,6 +50,9 @@ func (a *ApiHandler) setupRouter() *gin.Engine {
 	router.POST("/create", a.handleCreate)
 	router.DELETE("/drop", a.handleDrop)
 	router.PUT("/sync", a.handleSync)
+	router.GET("/databases", a.handleGetDatabases)
+	router.POST("/databases", a.handleCreateDatabases)
+	router.DELETE("/databases", a.handleDropDatabases)
 	router.GET("/healthcheck", func(c *gin.Context) {
 		c.JSON(200, gin.H{
 			"status": "UP",
@@ -68,7 +71,7 @@ func (a *ApiHandler) handleSync(c *gin.Context) {
 		})
 		return
 	}
-	source, err := mapMetastoreCode(request.Source)
+	source, err := mapMetastoreCode(request.Source, a.manager.Metastores())
 	if err != nil {
 		logrus.Warnf("sync bad request: %v", err)
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -76,7 +79,7 @@ func (a *ApiHandler) handleSync(c *gin.Context) {
 		})
 		return
 	}
-	target, err := mapMetastoreCode(request.Target)
+	target, err := mapMetastoreCode(request.Target, a.manager.Metastores())
 	if err != nil {
 		logrus.Warnf("sync bad request: %v", err)
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -84,7 +87,30 @@ func (a *ApiHandler) handleSync(c *gin.Context) {
 		})
 		return
 	}
-	err = a.manager.Sync(source, target, request.DbName, request.Tables, request.Delete)
+	pattern, err := syncDatabasePattern(request.DbName, request.Tables, request.AllDatabases, request.DatabasePattern)
+	if err != nil {
+		logrus.Warnf("sync bad request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+	if pattern != "" {
+		results, err := a.manager.SyncDatabases(source, target, pattern, request.Delete, request.CreateDatabase)
+		if err != nil {
+			logrus.Errorf("sync error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   err.Error(),
+				"results": results,
+			})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"results": results,
+		})
+		return
+	}
+	err = a.manager.Sync(source, target, request.DbName, request.Tables, request.Delete, request.CreateDatabase)
 	if err != nil {
 		logrus.Errorf("sync error: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -105,7 +131,7 @@ func (a *ApiHandler) handleDrop(c *gin.Context) {
 		})
 		return
 	}
-	code, err := mapMetastoreCode(request.Metastore)
+	code, err := mapMetastoreCode(request.Metastore, a.manager.Metastores())
 	if err != nil {
 		logrus.Warnf("drop bad request: %v", err)
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -134,7 +160,7 @@ func (a *ApiHandler) handleCreate(c *gin.Context) {
 		})
 		return
 	}
-	codes, err := mapMetastoreCodes(request.Metastores)
+	codes, err := mapMetastoreCodes(request.Metastores, a.manager.Metastores())
 	if err != nil {
 		logrus.Warnf("create bad request: %v", err)
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -153,6 +179,86 @@ func (a *ApiHandler) handleCreate(c *gin.Context) {
 	c.Status(http.StatusOK)
 }
 
+func (a *ApiHandler) handleGetDatabases(c *gin.Context) {
+	code, err := mapMetastoreCode(c.Query("metastore"), a.manager.Metastores())
+	if err != nil {
+		logrus.Warnf("get databases bad request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+	databases, err := a.manager.GetDatabases(code)
+	if err != nil {
+		logrus.Errorf("get databases error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"databases": databases,
+	})
+}
+
+func (a *ApiHandler) handleCreateDatabases(c *gin.Context) {
+	var request model.CreateDatabaseApiRequest
+	err := c.BindJSON(&request)
+	if err != nil {
+		logrus.Warnf("create databases bad request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+	codes, err := mapMetastoreCodes(request.Metastores, a.manager.Metastores())
+	if err != nil {
+		logrus.Warnf("create databases bad request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+	err = a.manager.CreateDatabases(codes, request.Databases)
+	if err != nil {
+		logrus.Errorf("create databases error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func (a *ApiHandler) handleDropDatabases(c *gin.Context) {
+	var request model.DropDatabaseApiRequest
+	err := c.BindJSON(&request)
+	if err != nil {
+		logrus.Warnf("drop databases bad request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+	code, err := mapMetastoreCode(request.Metastore, a.manager.Metastores())
+	if err != nil {
+		logrus.Warnf("drop databases bad request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+	err = a.manager.DropDatabases(code, request.Databases, request.Cascade)
+	if err != nil {
+		logrus.Errorf("drop databases error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
 func errorsAsStrings(errs []error) []string {
 	errStrings := make([]string, len(errs))
 	for i, err := range errs {
@@ -20,6 +20,13 @@ type ManagerMock struct {
 	createError error
 	syncCalls   []model.SyncApiRequest
 	syncError   error
+
+	syncDatabasesCalls []model.SyncApiRequest
+
+	getDatabasesCalls   []string
+	createDatabaseCalls []model.CreateDatabaseApiRequest
+	dropDatabaseCalls   []model.DropDatabaseApiRequest
+	databaseError       error
 }
 
 func (m *ManagerMock) Drop(metastore metastore.MetastoreCode, tables []model.DropArg) []error {
@@ -44,12 +51,13 @@ func (m *ManagerMock) Create(metastore []metastore.MetastoreCode, tables []model
 	return nil
 }
 
-func (m *ManagerMock) Sync(sourceMetastore metastore.MetastoreCode, targetMetastore metastore.MetastoreCode, dbName string, tables []string, delete bool) error {
+func (m *ManagerMock) Sync(sourceMetastore metastore.MetastoreCode, targetMetastore metastore.MetastoreCode, dbName string, tables []string, delete bool, createDatabase bool) error {
 	m.syncCalls = append(m.syncCalls, model.SyncApiRequest{
-		Source: string(sourceMetastore),
-		Target: string(targetMetastore),
-		DbName: dbName,
-		Delete: delete,
+		Source:         string(sourceMetastore),
+		Target:         string(targetMetastore),
+		DbName:         dbName,
+		Delete:         delete,
+		CreateDatabase: createDatabase,
 	})
 	if m.syncError != nil {
 		return m.syncError
@@ -57,6 +65,53 @@ func (m *ManagerMock) Sync(sourceMetastore metastore.MetastoreCode, targetMetast
 	return nil
 }
 
+func (m *ManagerMock) SyncDatabases(sourceMetastore metastore.MetastoreCode, targetMetastore metastore.MetastoreCode, dbPattern string, delete bool, createDatabase bool) ([]model.SyncResult, error) {
+	m.syncDatabasesCalls = append(m.syncDatabasesCalls, model.SyncApiRequest{
+		Source:          string(sourceMetastore),
+		Target:          string(targetMetastore),
+		DatabasePattern: dbPattern,
+		Delete:          delete,
+		CreateDatabase:  createDatabase,
+	})
+	if m.syncError != nil {
+		return []model.SyncResult{{Db: "pls", Error: m.syncError.Error()}}, m.syncError
+	}
+	return []model.SyncResult{{Db: "pls"}}, nil
+}
+
+func (m *ManagerMock) GetDatabases(metastore metastore.MetastoreCode) ([]string, error) {
+	m.getDatabasesCalls = append(m.getDatabasesCalls, string(metastore))
+	if m.databaseError != nil {
+		return nil, m.databaseError
+	}
+	return []string{"pls"}, nil
+}
+
+func (m *ManagerMock) CreateDatabases(metastores []metastore.MetastoreCode, databases []model.Database) error {
+	m.createDatabaseCalls = append(m.createDatabaseCalls, model.CreateDatabaseApiRequest{
+		Metastores: toStrings(metastores),
+		Databases:  databases,
+	})
+	return m.databaseError
+}
+
+func (m *ManagerMock) DropDatabases(metastore metastore.MetastoreCode, dbNames []string, cascade bool) error {
+	m.dropDatabaseCalls = append(m.dropDatabaseCalls, model.DropDatabaseApiRequest{
+		Metastore: string(metastore),
+		Databases: dbNames,
+		Cascade:   cascade,
+	})
+	return m.databaseError
+}
+
+func (m *ManagerMock) RegisterIceberg(metastores []metastore.MetastoreCode, dbName string, tableName string, tableLocation string) error {
+	return nil
+}
+
+func (m *ManagerMock) Metastores() []metastore.MetastoreCode {
+	return []metastore.MetastoreCode{"glue", "hive", "hive-dr"}
+}
+
 func TestApiHandler_shouldCreate(t *testing.T) {
 	type args struct {
 		mock    ManagerMock
@@ -227,6 +282,15 @@ func TestApiHandler_handleSync(t *testing.T) {
 				Delete: false,
 			},
 		},
+		{
+			mock: ManagerMock{},
+			request: model.SyncApiRequest{
+				Source:         "hive",
+				Target:         "glue",
+				DbName:         "test",
+				CreateDatabase: true,
+			},
+		},
 		{
 			mock:    ManagerMock{},
 			wantErr: true,
@@ -277,6 +341,296 @@ func TestApiHandler_handleSync(t *testing.T) {
 	}
 }
 
+func TestApiHandler_handleGetDatabases(t *testing.T) {
+	tests := []struct {
+		name     string
+		mock     ManagerMock
+		query    string
+		wantCode int
+	}{
+		{
+			name:     "shouldListDatabases",
+			mock:     ManagerMock{},
+			query:    "?metastore=glue",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "shouldErrorWhenManagerError",
+			mock:     ManagerMock{databaseError: fmt.Errorf("error")},
+			query:    "?metastore=glue",
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:     "shouldErrorWhenNoSupportedMetastore",
+			mock:     ManagerMock{},
+			query:    "?metastore=no",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := ApiHandler{manager: &tt.mock}
+			router := handler.setupRouter()
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/databases"+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantCode, w.Code)
+			if tt.wantCode == http.StatusOK {
+				require.JSONEq(t, `{"databases":["pls"]}`, w.Body.String())
+			}
+			if tt.wantCode == http.StatusBadRequest {
+				require.Len(t, tt.mock.getDatabasesCalls, 0)
+			} else {
+				require.Equal(t, []string{"glue"}, tt.mock.getDatabasesCalls)
+			}
+		})
+	}
+}
+
+func TestApiHandler_handleCreateDatabases(t *testing.T) {
+	tests := []struct {
+		name     string
+		mock     ManagerMock
+		request  model.CreateDatabaseApiRequest
+		wantCode int
+	}{
+		{
+			name:     "shouldCreateDatabases",
+			mock:     ManagerMock{},
+			request:  getCreateDatabaseApiRequest([]string{"hive", "glue"}),
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "shouldErrorWhenManagerError",
+			mock:     ManagerMock{databaseError: fmt.Errorf("error")},
+			request:  getCreateDatabaseApiRequest([]string{"hive", "glue"}),
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:     "shouldErrorWhenNoSupportedMetastore",
+			mock:     ManagerMock{},
+			request:  getCreateDatabaseApiRequest([]string{"no"}),
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := ApiHandler{manager: &tt.mock}
+			router := handler.setupRouter()
+			w := httptest.NewRecorder()
+			marshal, err := json.Marshal(tt.request)
+			require.NoError(t, err)
+			req, _ := http.NewRequest("POST", "/databases", strings.NewReader(string(marshal)))
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantCode, w.Code)
+			if tt.wantCode == http.StatusBadRequest {
+				require.Len(t, tt.mock.createDatabaseCalls, 0)
+			} else {
+				require.Equal(t, []model.CreateDatabaseApiRequest{tt.request}, tt.mock.createDatabaseCalls)
+			}
+		})
+	}
+}
+
+func TestApiHandler_handleDropDatabases(t *testing.T) {
+	tests := []struct {
+		name     string
+		mock     ManagerMock
+		request  model.DropDatabaseApiRequest
+		wantCode int
+	}{
+		{
+			name: "shouldDropDatabases",
+			mock: ManagerMock{},
+			request: model.DropDatabaseApiRequest{
+				Metastore: "glue",
+				Databases: []string{"pls"},
+				Cascade:   true,
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "shouldErrorWhenManagerError",
+			mock: ManagerMock{databaseError: fmt.Errorf("error")},
+			request: model.DropDatabaseApiRequest{
+				Metastore: "glue",
+				Databases: []string{"pls"},
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name: "shouldErrorWhenNoSupportedMetastore",
+			mock: ManagerMock{},
+			request: model.DropDatabaseApiRequest{
+				Metastore: "no",
+				Databases: []string{"pls"},
+			},
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := ApiHandler{manager: &tt.mock}
+			router := handler.setupRouter()
+			w := httptest.NewRecorder()
+			marshal, err := json.Marshal(tt.request)
+			require.NoError(t, err)
+			req, _ := http.NewRequest("DELETE", "/databases", strings.NewReader(string(marshal)))
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantCode, w.Code)
+			if tt.wantCode == http.StatusBadRequest {
+				require.Len(t, tt.mock.dropDatabaseCalls, 0)
+			} else {
+				require.Equal(t, []model.DropDatabaseApiRequest{tt.request}, tt.mock.dropDatabaseCalls)
+			}
+		})
+	}
+}
+
+func TestApiHandler_handleSyncDatabases(t *testing.T) {
+	tests := []struct {
+		name        string
+		mock        ManagerMock
+		request     model.SyncApiRequest
+		wantCode    int
+		wantPattern string
+	}{
+		{
+			name: "shouldSyncAllDatabases",
+			mock: ManagerMock{},
+			request: model.SyncApiRequest{
+				Source:       "hive",
+				Target:       "glue",
+				AllDatabases: true,
+			},
+			wantCode:    http.StatusOK,
+			wantPattern: "*",
+		},
+		{
+			name: "shouldSyncNamedMetastores",
+			mock: ManagerMock{},
+			request: model.SyncApiRequest{
+				Source:       "hive",
+				Target:       "hive-dr",
+				AllDatabases: true,
+			},
+			wantCode:    http.StatusOK,
+			wantPattern: "*",
+		},
+		{
+			name: "shouldErrorWhenMetastoreNotConfigured",
+			mock: ManagerMock{},
+			request: model.SyncApiRequest{
+				Source:       "hive",
+				Target:       "glue-us-east-1",
+				AllDatabases: true,
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "shouldSyncDatabasePattern",
+			mock: ManagerMock{},
+			request: model.SyncApiRequest{
+				Source:          "hive",
+				Target:          "glue",
+				DatabasePattern: "analytics_*",
+			},
+			wantCode:    http.StatusOK,
+			wantPattern: "analytics_*",
+		},
+		{
+			name: "shouldErrorWhenManagerError",
+			mock: ManagerMock{syncError: fmt.Errorf("error")},
+			request: model.SyncApiRequest{
+				Source:       "hive",
+				Target:       "glue",
+				AllDatabases: true,
+			},
+			wantCode:    http.StatusInternalServerError,
+			wantPattern: "*",
+		},
+		{
+			name: "shouldErrorWhenDatabaseAndPattern",
+			mock: ManagerMock{},
+			request: model.SyncApiRequest{
+				Source:          "hive",
+				Target:          "glue",
+				DbName:          "pls",
+				DatabasePattern: "analytics_*",
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "shouldErrorWhenTablesAndAllDatabases",
+			mock: ManagerMock{},
+			request: model.SyncApiRequest{
+				Source:       "hive",
+				Target:       "glue",
+				Tables:       []string{"table"},
+				AllDatabases: true,
+			},
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := ApiHandler{manager: &tt.mock}
+			router := handler.setupRouter()
+			w := httptest.NewRecorder()
+			marshal, err := json.Marshal(tt.request)
+			require.NoError(t, err)
+			req, _ := http.NewRequest("PUT", "/sync", strings.NewReader(string(marshal)))
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantCode, w.Code)
+			require.Len(t, tt.mock.syncCalls, 0)
+			if tt.wantCode == http.StatusBadRequest {
+				require.Len(t, tt.mock.syncDatabasesCalls, 0)
+				return
+			}
+			require.Len(t, tt.mock.syncDatabasesCalls, 1)
+			require.Equal(t, tt.wantPattern, tt.mock.syncDatabasesCalls[0].DatabasePattern)
+			var body struct {
+				Results []model.SyncResult `json:"results"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			require.Len(t, body.Results, 1)
+		})
+	}
+}
+
+func Test_syncDatabasePattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		dbName  string
+		tables  []string
+		all     bool
+		pattern string
+		want    string
+		wantErr bool
+	}{
+		{name: "shouldSingleDatabase", dbName: "pls", tables: []string{"table"}, want: ""},
+		{name: "shouldAllDatabases", all: true, want: "*"},
+		{name: "shouldPattern", pattern: "analytics_*", want: "analytics_*"},
+		{name: "shouldErrorWhenAllAndPattern", all: true, pattern: "analytics_*", wantErr: true},
+		{name: "shouldErrorWhenDatabaseAndPattern", dbName: "pls", pattern: "analytics_*", wantErr: true},
+		{name: "shouldErrorWhenTablesAndPattern", tables: []string{"table"}, pattern: "analytics_*", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := syncDatabasePattern(tt.dbName, tt.tables, tt.all, tt.pattern)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("syncDatabasePattern() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestApiHandler_healthcheck(t *testing.T) {
 	handler := ApiHandler{manager: &ManagerMock{}}
 	router := handler.setupRouter()
@@ -320,6 +674,20 @@ func getCreateApiRequest(met []string) model.CreateApiRequest {
 	}
 }
 
+func getCreateDatabaseApiRequest(met []string) model.CreateDatabaseApiRequest {
+	return model.CreateDatabaseApiRequest{
+		Metastores: met,
+		Databases: []model.Database{
+			{
+				Name:        "pls",
+				Description: "pls database",
+				Location:    "s3://bucket/pls",
+				Parameters:  map[string]string{"team": "data"},
+			},
+		},
+	}
+}
+
 func toStrings(codes []metastore.MetastoreCode) []string {
 	toReturn := make([]string, len(codes))
 	for i, code := range codes {
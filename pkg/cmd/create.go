@@ -31,15 +31,15 @@ func create(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	codes, tables, err := mapCreateCommands()
+	codes, tables, err := mapCreateCommands(metaman.Metastores())
 	if err != nil {
 		return err
 	}
 	return metaman.Create(codes, tables)
 }
 
-func mapCreateCommands() ([]metastore.MetastoreCode, []model.DatabaseTables, error) {
-	codes, err := mapMetastoreCodes(metastoreNames)
+func mapCreateCommands(metastores []metastore.MetastoreCode) ([]metastore.MetastoreCode, []model.DatabaseTables, error) {
+	codes, err := mapMetastoreCodes(metastoreNames, metastores)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -55,10 +55,10 @@ func mapCreateCommands() ([]metastore.MetastoreCode, []model.DatabaseTables, err
 	return codes, args, nil
 }
 
-func mapMetastoreCodes(names []string) ([]metastore.MetastoreCode, error) {
+func mapMetastoreCodes(names []string, metastores []metastore.MetastoreCode) ([]metastore.MetastoreCode, error) {
 	codes := make([]metastore.MetastoreCode, len(names))
 	for i, name := range names {
-		code, err := mapMetastoreCode(name)
+		code, err := mapMetastoreCode(name, metastores)
 		if err != nil {
 			return nil, err
 		}
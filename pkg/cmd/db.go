@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/the-Data-Appeal-Company/metaman/pkg/model"
+)
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "manage databases",
+	Long:  `list, create and drop databases in the given metastores`,
+}
+
+var dbListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list databases",
+	Long:  `list databases of the given metastore`,
+	RunE:  dbList,
+}
+
+var dbCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "create database",
+	Long:  `create a database in the given metastores`,
+	RunE:  dbCreate,
+}
+
+var dbDropCmd = &cobra.Command{
+	Use:   "drop",
+	Short: "drop database",
+	Long: `drop a database from the given metastore,
+		an option could be passed to also drop the tables it contains (default value false)`,
+	RunE: dbDrop,
+}
+
+var (
+	dbLocation    string
+	dbDescription string
+	dbParameters  map[string]string
+	dbCascade     bool
+)
+
+func init() {
+	dbListCmd.Flags().StringVarP(&metastoreName, "metastore", "m", "", "metastore")
+
+	dbCreateCmd.Flags().StringSliceVarP(&metastoreNames, "metastores", "m", []string{}, "list of metastore")
+	dbCreateCmd.Flags().StringVarP(&database, "database", "d", "", "database name")
+	dbCreateCmd.Flags().StringVar(&dbLocation, "location", "", "database location")
+	dbCreateCmd.Flags().StringVar(&dbDescription, "description", "", "database description")
+	dbCreateCmd.Flags().StringToStringVar(&dbParameters, "parameters", map[string]string{}, "database parameters as key=value pairs")
+
+	dbDropCmd.Flags().StringVarP(&metastoreName, "metastore", "m", "", "metastore")
+	dbDropCmd.Flags().StringVarP(&database, "database", "d", "", "database name")
+	dbDropCmd.Flags().BoolVar(&dbCascade, "cascade", false, "drop database tables too")
+
+	dbCmd.AddCommand(dbListCmd)
+	dbCmd.AddCommand(dbCreateCmd)
+	dbCmd.AddCommand(dbDropCmd)
+}
+
+func dbList(cmd *cobra.Command, args []string) error {
+	metaman, err := getMetastoreManager()
+	if err != nil {
+		return err
+	}
+	code, err := mapMetastoreCode(metastoreName, metaman.Metastores())
+	if err != nil {
+		return err
+	}
+	databases, err := metaman.GetDatabases(code)
+	if err != nil {
+		return err
+	}
+	for _, db := range databases {
+		fmt.Fprintln(cmd.OutOrStdout(), db)
+	}
+	return nil
+}
+
+func dbCreate(cmd *cobra.Command, args []string) error {
+	metaman, err := getMetastoreManager()
+	if err != nil {
+		return err
+	}
+	codes, err := mapMetastoreCodes(metastoreNames, metaman.Metastores())
+	if err != nil {
+		return err
+	}
+	return metaman.CreateDatabases(codes, []model.Database{
+		{
+			Name:        database,
+			Description: dbDescription,
+			Location:    dbLocation,
+			Parameters:  dbParameters,
+		},
+	})
+}
+
+func dbDrop(cmd *cobra.Command, args []string) error {
+	metaman, err := getMetastoreManager()
+	if err != nil {
+		return err
+	}
+	code, err := mapMetastoreCode(metastoreName, metaman.Metastores())
+	if err != nil {
+		return err
+	}
+	return metaman.DropDatabases(code, []string{database}, dbCascade)
+}
@@ -33,7 +33,7 @@ func drop(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	code, tables, err := mapDropCommands()
+	code, tables, err := mapDropCommands(metaman.Metastores())
 	if err != nil {
 		return err
 	}
@@ -45,8 +45,8 @@ func drop(cmd *cobra.Command, args []string) error {
 	return result
 }
 
-func mapDropCommands() (metastore.MetastoreCode, []model.DropArg, error) {
-	code, err := mapMetastoreCode(metastoreName)
+func mapDropCommands(metastores []metastore.MetastoreCode) (metastore.MetastoreCode, []model.DropArg, error) {
+	code, err := mapMetastoreCode(metastoreName, metastores)
 	if err != nil {
 		return "", nil, err
 	}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"path"
+	"strings"
+)
+
+var icebergCmd = &cobra.Command{
+	Use:   "iceberg",
+	Short: "manage iceberg tables",
+	Long:  `manage iceberg tables in the given metastores`,
+}
+
+var icebergRegisterCmd = &cobra.Command{
+	Use:   "register",
+	Short: "register iceberg table",
+	Long: `register an iceberg table in the given metastores knowing only its root location,
+		the table points to its latest metadata file found under the metadata/ prefix`,
+	RunE: icebergRegister,
+}
+
+var (
+	icebergLocation  string
+	icebergTableName string
+)
+
+func init() {
+	icebergRegisterCmd.Flags().StringSliceVarP(&metastoreNames, "metastores", "m", []string{}, "list of metastore")
+	icebergRegisterCmd.Flags().StringVarP(&database, "database", "d", "", "database name")
+	icebergRegisterCmd.Flags().StringVar(&icebergLocation, "location", "", "table root location, e.g. s3://bucket/db/table")
+	icebergRegisterCmd.Flags().StringVar(&icebergTableName, "table", "", "table name (default last element of the location)")
+
+	icebergCmd.AddCommand(icebergRegisterCmd)
+}
+
+func icebergRegister(cmd *cobra.Command, args []string) error {
+	if icebergLocation == "" {
+		return fmt.Errorf("table location is required")
+	}
+	metaman, err := getMetastoreManager()
+	if err != nil {
+		return err
+	}
+	codes, err := mapMetastoreCodes(metastoreNames, metaman.Metastores())
+	if err != nil {
+		return err
+	}
+	tableName := icebergTableName
+	if tableName == "" {
+		tableName = path.Base(strings.TrimSuffix(icebergLocation, "/"))
+	}
+	return metaman.RegisterIceberg(codes, database, tableName, icebergLocation)
+}
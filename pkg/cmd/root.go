@@ -2,11 +2,17 @@ package cmd
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"database/sql"
 	"fmt"
+	awsV2 "github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	stscredsV2 "github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/session"
 	awsGlue "github.com/aws/aws-sdk-go/service/glue"
 	_ "github.com/go-sql-driver/mysql"
@@ -15,22 +21,33 @@ import (
 	"github.com/spf13/cobra"
 	metamanConf "github.com/the-Data-Appeal-Company/metaman/pkg/config"
 	"github.com/the-Data-Appeal-Company/metaman/pkg/deleter"
+	"github.com/the-Data-Appeal-Company/metaman/pkg/iceberg"
 	"github.com/the-Data-Appeal-Company/metaman/pkg/manager"
 	"github.com/the-Data-Appeal-Company/metaman/pkg/metastore"
 	"log"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// defaultIcebergRestTimeout bounds the iceberg rest catalog requests when no timeout is configured.
+const defaultIcebergRestTimeout = 30 * time.Second
+
 var ConfPath string
 
 var rootCmd = &cobra.Command{
 	Use:   "metaman",
 	Short: "metaman is the command-line tool/api to interact with metastore",
 	Long: `metaman is the command-line tool/api to interact with metastore.
-Currently supported metastore are: Glue, Hive.
+Currently supported metastore are: Glue, Hive, Iceberg REST catalog, directories of yaml/json files.
 Supported operations are:
 - create tables
 - drop tables along with data
-- sync different metastore`,
+- sync different metastore
+- list, create and drop databases`,
 }
 
 func init() {
@@ -45,6 +62,8 @@ func init() {
 	rootCmd.AddCommand(createCmd)
 	rootCmd.AddCommand(syncCmd)
 	rootCmd.AddCommand(apiCmd)
+	rootCmd.AddCommand(dbCmd)
+	rootCmd.AddCommand(icebergCmd)
 }
 
 func Execute() {
@@ -59,54 +78,245 @@ func createAwsSession(conf metamanConf.Aws) *session.Session {
 	}))
 }
 
-func getMetastoreManager() (*manager.HiveGlueManager, error) {
-	configuration, err := metamanConf.FromYaml(ConfPath)
+// getGlueClient returns the glue client, with the credentials of the assumed role when configured.
+func getGlueClient(sess *session.Session, assumeRole metamanConf.AssumeRole) *awsGlue.Glue {
+	if assumeRole.RoleArn == "" {
+		return awsGlue.New(sess)
+	}
+	credentials := stscreds.NewCredentials(sess, assumeRole.RoleArn, func(p *stscreds.AssumeRoleProvider) {
+		if assumeRole.ExternalId != "" {
+			p.ExternalID = aws.String(assumeRole.ExternalId)
+		}
+		if assumeRole.SessionName != "" {
+			p.RoleSessionName = assumeRole.SessionName
+		}
+	})
+	return awsGlue.New(sess, &aws.Config{Credentials: credentials})
+}
+
+func getHiveFactory(conf metamanConf.Hive) (metastore.HiveFactory, error) {
+	addresses, err := conf.Addresses()
 	if err != nil {
 		return nil, err
 	}
-	ctx := context.Background()
-	s3Client, err := getS3Client(ctx, configuration)
+	connect, err := getHiveConnector(conf)
 	if err != nil {
 		return nil, err
 	}
-	fileDeleter := deleter.NewFileDeleterS3(s3Client)
-	sess := createAwsSession(configuration.Aws)
+	factory := connect(addresses[0])
+	if len(addresses) > 1 {
+		endpoints := make([]metastore.HiveEndpoint, 0, len(addresses))
+		for _, address := range addresses {
+			endpoints = append(endpoints, metastore.HiveEndpoint{
+				Address: net.JoinHostPort(address.Host, strconv.Itoa(address.Port)),
+				Factory: connect(address),
+			})
+		}
+		factory = metastore.NewHiveFailoverFactory(endpoints, conf.Failover.Randomize, conf.Failover.FailureThreshold, conf.Failover.Cooldown)
+	}
+	if conf.Pool.MaxSize > 0 {
+		factory = metastore.NewHivePoolFactory(factory, conf.Pool.MaxSize, conf.Pool.IdleTimeout, conf.Pool.BorrowTimeout, conf.Pool.HealthCheckIdle)
+	}
+	return factory, nil
+}
+
+// getHiveConnector returns the factory of the connections to a metastore, with the configured TLS and SASL.
+func getHiveConnector(conf metamanConf.Hive) (func(address metamanConf.HiveAddress) metastore.HiveFactory, error) {
+	if conf.Auth.Mechanism == "" && !conf.Tls.Enabled {
+		return func(address metamanConf.HiveAddress) metastore.HiveFactory {
+			return metastore.NewHiveAlwaysRecreateFactory(address.Host, address.Port)
+		}, nil
+	}
+	var tlsConfig *tls.Config
+	if conf.Tls.Enabled {
+		var err error
+		tlsConfig, err = getHiveTlsConfig(conf.Tls)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var sasl func(host string) metastore.SaslMechanism
+	switch strings.ToLower(conf.Auth.Mechanism) {
+	case "":
+		sasl = func(string) metastore.SaslMechanism { return nil }
+	case "plain":
+		plain := metastore.NewSaslPlain(conf.Auth.Username, conf.Auth.Password)
+		sasl = func(string) metastore.SaslMechanism { return plain }
+	case "kerberos":
+		krb5Conf := conf.Auth.Krb5Conf
+		if krb5Conf == "" {
+			krb5Conf = "/etc/krb5.conf"
+		}
+		servicePrincipal := conf.Auth.ServicePrincipal
+		if servicePrincipal == "" {
+			servicePrincipal = "hive/_HOST"
+		}
+		krb, err := metastore.NewKerberosClient(conf.Auth.Principal, conf.Auth.Keytab, krb5Conf)
+		if err != nil {
+			return nil, err
+		}
+		sasl = func(host string) metastore.SaslMechanism {
+			return metastore.NewSaslGssapi(krb, strings.ReplaceAll(servicePrincipal, "_HOST", host))
+		}
+	default:
+		return nil, fmt.Errorf("unsupported hive auth mechanism %s", conf.Auth.Mechanism)
+	}
+	return func(address metamanConf.HiveAddress) metastore.HiveFactory {
+		return metastore.NewHiveTransportFactory(address.Host, address.Port, tlsConfig, sasl(address.Host))
+	}, nil
+}
+
+func getHiveTlsConfig(conf metamanConf.HiveTls) (*tls.Config, error) {
+	tlsConfig := &tls.Config{ServerName: conf.ServerName, InsecureSkipVerify: conf.InsecureSkipVerify}
+	if conf.CaFile != "" {
+		ca, err := os.ReadFile(conf.CaFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificate found in %s", conf.CaFile)
+		}
+	}
+	return tlsConfig, nil
+}
+
+func getMetastoreManager() (*manager.HiveGlueManager, error) {
+	configuration, err := metamanConf.FromYaml(ConfPath)
 	if err != nil {
 		return nil, err
 	}
-
-	db, err := sql.Open(configuration.Db.Driver, configuration.Db.ConnectionString())
+	ctx := context.Background()
+	s3Client, err := getS3Client(ctx, configuration.Aws.Region, metamanConf.AssumeRole{})
 	if err != nil {
 		return nil, err
 	}
-	aux := metastore.NewPgAuxInfoRetriever(db)
+	builder := &metastoreBuilder{
+		ctx:           ctx,
+		configuration: configuration,
+		s3Client:      s3Client,
+		sess:          createAwsSession(configuration.Aws),
+		dbs:           make(map[string]*sql.DB),
+	}
+	metastores := make(map[metastore.MetastoreCode]metastore.Metastore)
+	for name, instance := range configuration.MetastoreInstances() {
+		meta, err := builder.build(name, instance)
+		if err != nil {
+			return nil, fmt.Errorf("metastore %s: %w", name, err)
+		}
+		metastores[metastore.MetastoreCode(name)] = meta
+	}
+	icebergReader := iceberg.NewMetadataReader(iceberg.NewLocationFileReader(s3Client))
+	return manager.NewHiveGlueManager(metastore.NewPoolMetastore(metastores), icebergReader, configuration.Iceberg.PreventRollback), nil
+}
+
+// metastoreBuilder builds the configured metastore instances, sharing the clients they have in common.
+type metastoreBuilder struct {
+	ctx           context.Context
+	configuration metamanConf.Conf
+	s3Client      *s3.Client
+	sess          *session.Session
+	dbs           map[string]*sql.DB
+}
+
+func (b *metastoreBuilder) build(name string, instance metamanConf.Metastore) (metastore.Metastore, error) {
+	exclude := b.configuration.TableParameters.Exclude
+	switch instance.Type {
+	case metamanConf.HiveType:
+		db, dialect, err := b.db(instance.Db)
+		if err != nil {
+			return nil, err
+		}
+		factory, err := getHiveFactory(instance.Hive)
+		if err != nil {
+			return nil, err
+		}
+		return metastore.NewHiveMetaStore(factory, deleter.NewFileDeleterS3(b.s3Client), metastore.NewAuxInfoRetriever(dialect, db), exclude), nil
+	case metamanConf.GlueType:
+		region := instance.Glue.Region
+		if region == "" {
+			region = b.configuration.Aws.Region
+		}
+		s3Client := b.s3Client
+		if instance.Glue.AssumeRole.RoleArn != "" || region != b.configuration.Aws.Region {
+			var err error
+			s3Client, err = getS3Client(b.ctx, region, instance.Glue.AssumeRole)
+			if err != nil {
+				return nil, err
+			}
+		}
+		glueClient := getGlueClient(b.sess.Copy(&aws.Config{Region: aws.String(region)}), instance.Glue.AssumeRole)
+		return metastore.NewGlueMetaStore(glueClient, instance.Glue.CatalogId, deleter.NewFileDeleterS3(s3Client), exclude), nil
+	case metamanConf.IcebergRestType:
+		rest := instance.IcebergRest
+		if rest.Uri == "" {
+			return nil, fmt.Errorf("iceberg rest metastore %s has no uri", name)
+		}
+		timeout := rest.Timeout
+		if timeout == 0 {
+			timeout = defaultIcebergRestTimeout
+		}
+		return metastore.NewIcebergRestMetaStore(&http.Client{Timeout: timeout}, rest.Uri, rest.Prefix, rest.Token, exclude), nil
+	case metamanConf.HiveDbType:
+		db, dialect, err := b.db(instance.Db)
+		if err != nil {
+			return nil, err
+		}
+		return metastore.NewHiveDbMetaStore(db, dialect, instance.HiveDb.Catalog, exclude), nil
+	case metamanConf.FileType:
+		return metastore.NewFileMetaStore(instance.File.Path, instance.File.Format, exclude)
+	default:
+		return nil, fmt.Errorf("metastore type %s not supported", instance.Type)
+	}
+}
 
-	factory := metastore.NewHiveAlwaysRecreateFactory(configuration.Metastore.Hive.Url, configuration.Metastore.Hive.Port)
-	pool := metastore.NewPoolMetastore(
-		metastore.NewHiveMetaStore(factory, fileDeleter, aux),
-		metastore.NewGlueMetaStore(awsGlue.New(sess), fileDeleter),
-	)
-	return manager.NewHiveGlueManager(pool), nil
+// db opens the metastore database, the top level one when conf is nil, once for each connection string.
+func (b *metastoreBuilder) db(conf *metamanConf.Db) (*sql.DB, metastore.SqlDialect, error) {
+	if conf == nil {
+		conf = &b.configuration.Db
+	}
+	dialect, err := metastore.DialectFromDriver(conf.Driver)
+	if err != nil {
+		return nil, "", err
+	}
+	connectionString := conf.ConnectionString()
+	if db, ok := b.dbs[connectionString]; ok {
+		return db, dialect, nil
+	}
+	db, err := sql.Open(conf.Driver, connectionString)
+	if err != nil {
+		return nil, "", err
+	}
+	b.dbs[connectionString] = db
+	return db, dialect, nil
 }
 
-func getS3Client(ctx context.Context, configuration metamanConf.Conf) (*s3.Client, error) {
-	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(configuration.Aws.Region))
+func getS3Client(ctx context.Context, region string, assumeRole metamanConf.AssumeRole) (*s3.Client, error) {
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
 	if err != nil {
 		return nil, err
 	}
+	if assumeRole.RoleArn != "" {
+		provider := stscredsV2.NewAssumeRoleProvider(sts.NewFromConfig(cfg), assumeRole.RoleArn, func(o *stscredsV2.AssumeRoleOptions) {
+			if assumeRole.ExternalId != "" {
+				o.ExternalID = awsV2.String(assumeRole.ExternalId)
+			}
+			if assumeRole.SessionName != "" {
+				o.RoleSessionName = assumeRole.SessionName
+			}
+		})
+		cfg.Credentials = awsV2.NewCredentialsCache(provider)
+	}
 	s3Client := s3.NewFromConfig(cfg)
 	return s3Client, nil
 }
 
-func mapMetastoreCode(name string) (metastore.MetastoreCode, error) {
-	var code metastore.MetastoreCode
-	switch metastore.MetastoreCode(name) {
-	case metastore.GLUE:
-		code = metastore.GLUE
-	case metastore.HIVE:
-		code = metastore.HIVE
-	default:
-		return "", fmt.Errorf("metastore %s not supported", name)
+// mapMetastoreCode checks that name is one of the configured metastores.
+func mapMetastoreCode(name string, metastores []metastore.MetastoreCode) (metastore.MetastoreCode, error) {
+	for _, code := range metastores {
+		if code == metastore.MetastoreCode(name) {
+			return code, nil
+		}
 	}
-	return code, nil
+	return "", fmt.Errorf("metastore %s not supported", name)
 }
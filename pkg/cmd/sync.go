@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"fmt"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/the-Data-Appeal-Company/metaman/pkg/metastore"
 )
@@ -9,8 +11,10 @@ var syncCmd = &cobra.Command{
 	Use:   "sync",
 	Short: "sync tables between metastore",
 	Long: `sync tables between metastore in the given database,
+		tables existing in both metastores are updated when columns, partitions, location or format differ,
 		an option could be passed to also delete tables that exist only in the target metastore
-		(default value false)`,
+		(default value false),
+		instead of a single database every database or the databases matching a pattern could be synced`,
 	RunE: sync,
 }
 
@@ -19,6 +23,9 @@ var (
 	targetMetastore string
 	sourceTables    []string
 	deleteTables    bool
+	createDatabase  bool
+	allDatabases    bool
+	databasePattern string
 )
 
 func init() {
@@ -27,6 +34,9 @@ func init() {
 	syncCmd.Flags().StringVarP(&database, "database", "d", "", "database name")
 	syncCmd.Flags().StringSliceVarP(&sourceTables, "tables", "", []string{}, "list of tables to sync to target")
 	syncCmd.Flags().BoolVar(&deleteTables, "delete-tables", false, "delete tables from target non existing in source")
+	syncCmd.Flags().BoolVar(&createDatabase, "create-database", false, "create database in target if missing")
+	syncCmd.Flags().BoolVar(&allDatabases, "all-databases", false, "sync all source databases")
+	syncCmd.Flags().StringVar(&databasePattern, "database-pattern", "", "sync source databases matching the pattern, e.g. 'analytics_*'")
 }
 
 func sync(cmd *cobra.Command, args []string) error {
@@ -34,19 +44,55 @@ func sync(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	source, target, err := mapSyncCommands()
+	source, target, err := mapSyncCommands(metaman.Metastores())
 	if err != nil {
 		return err
 	}
-	return metaman.Sync(source, target, database, sourceTables, deleteTables)
+	pattern, err := syncDatabasePattern(database, sourceTables, allDatabases, databasePattern)
+	if err != nil {
+		return err
+	}
+	if pattern == "" {
+		return metaman.Sync(source, target, database, sourceTables, deleteTables, createDatabase)
+	}
+	results, err := metaman.SyncDatabases(source, target, pattern, deleteTables, createDatabase)
+	for _, result := range results {
+		if result.Error != "" {
+			logrus.Errorf("db: %s, sync failed: %s", result.Db, result.Error)
+		} else {
+			logrus.Infof("db: %s, sync succeeded", result.Db)
+		}
+	}
+	return err
+}
+
+// syncDatabasePattern returns the pattern of the databases to sync,
+// or an empty string when a single database has been requested.
+func syncDatabasePattern(dbName string, tables []string, all bool, pattern string) (string, error) {
+	if all && pattern != "" {
+		return "", fmt.Errorf("all databases and database pattern cannot be used together")
+	}
+	if all {
+		pattern = "*"
+	}
+	if pattern == "" {
+		return "", nil
+	}
+	if dbName != "" {
+		return "", fmt.Errorf("database cannot be used together with all databases or database pattern")
+	}
+	if len(tables) > 0 {
+		return "", fmt.Errorf("tables can only be synced for a single database")
+	}
+	return pattern, nil
 }
 
-func mapSyncCommands() (metastore.MetastoreCode, metastore.MetastoreCode, error) {
-	source, err := mapMetastoreCode(sourceMetastore)
+func mapSyncCommands(metastores []metastore.MetastoreCode) (metastore.MetastoreCode, metastore.MetastoreCode, error) {
+	source, err := mapMetastoreCode(sourceMetastore, metastores)
 	if err != nil {
 		return "", "", err
 	}
-	target, err := mapMetastoreCode(targetMetastore)
+	target, err := mapMetastoreCode(targetMetastore, metastores)
 	if err != nil {
 		return "", "", err
 	}
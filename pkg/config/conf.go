@@ -2,27 +2,176 @@ package config
 
 import (
 	"fmt"
+	"github.com/go-sql-driver/mysql"
+	"net"
 	"net/url"
+	"strconv"
+	"time"
 )
 
 type Conf struct {
-	Metastore  Metastore  `yaml:"metastore"`
-	Aws        Aws        `yaml:"aws"`
-	Prometheus Prometheus `yaml:"prometheus"`
-	Db         Db         `yaml:"db"`
+	Metastores      map[string]Metastore `yaml:"metastore"`
+	Aws             Aws                  `yaml:"aws"`
+	Prometheus      Prometheus           `yaml:"prometheus"`
+	Db              Db                   `yaml:"db"`
+	TableParameters TableParameters      `yaml:"table_parameters"`
+	Iceberg         Iceberg              `yaml:"iceberg"`
 }
 
 type Aws struct {
 	Region string `yaml:"region"`
 }
 
+// metastore types
+const (
+	HiveType        = "hive"
+	GlueType        = "glue"
+	IcebergRestType = "iceberg_rest"
+	HiveDbType      = "hive_db"
+	FileType        = "file"
+)
+
+// Metastore is a named metastore instance: its type, defaulting to its name, and the settings of that type.
 type Metastore struct {
-	Hive Hive `yaml:"hive"`
+	Type        string      `yaml:"type"`
+	Hive        Hive        `yaml:",inline"`
+	Glue        Glue        `yaml:",inline"`
+	IcebergRest IcebergRest `yaml:",inline"`
+	HiveDb      HiveDb      `yaml:",inline"`
+	File        File        `yaml:",inline"`
+	// Db is the metastore database of hive and hive_db instances, the top level db when nil.
+	Db *Db `yaml:"db"`
+}
+
+// MetastoreInstances returns the metastores by name with their type set. When no instance is named
+// so, a glue one is added with the aws settings and a hive_db one when the top level db is configured.
+func (c Conf) MetastoreInstances() map[string]Metastore {
+	instances := make(map[string]Metastore, len(c.Metastores)+2)
+	for name, instance := range c.Metastores {
+		if instance.Type == "" {
+			instance.Type = name
+		}
+		instances[name] = instance
+	}
+	if _, ok := instances[GlueType]; !ok {
+		instances[GlueType] = Metastore{Type: GlueType}
+	}
+	if _, ok := instances[HiveDbType]; !ok && c.Db != (Db{}) {
+		instances[HiveDbType] = Metastore{Type: HiveDbType}
+	}
+	return instances
 }
 
 type Hive struct {
-	Url  string `yaml:"url"`
-	Port int    `yaml:"port"`
+	Url      string       `yaml:"url"`
+	Port     int          `yaml:"port"`
+	Uris     []string     `yaml:"uris"`
+	Failover HiveFailover `yaml:"failover"`
+	Pool     HivePool     `yaml:"pool"`
+	Auth     HiveAuth     `yaml:"auth"`
+	Tls      HiveTls      `yaml:"tls"`
+}
+
+// HiveAuth is the SASL authentication to the metastore: none when mechanism is empty,
+// plain with username and password, or kerberos with principal and keytab.
+type HiveAuth struct {
+	Mechanism        string `yaml:"mechanism"`
+	Username         string `yaml:"username"`
+	Password         string `yaml:"password"`
+	Principal        string `yaml:"principal"`
+	Keytab           string `yaml:"keytab"`
+	Krb5Conf         string `yaml:"krb5_conf"`
+	ServicePrincipal string `yaml:"service_principal"`
+}
+
+type HiveTls struct {
+	Enabled            bool   `yaml:"enabled"`
+	CaFile             string `yaml:"ca_file"`
+	ServerName         string `yaml:"server_name"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+}
+
+// HiveFailover drives the choice among uris: after failure_threshold consecutive failures
+// a metastore is skipped for cooldown.
+type HiveFailover struct {
+	Randomize        bool          `yaml:"randomize"`
+	FailureThreshold int           `yaml:"failure_threshold"`
+	Cooldown         time.Duration `yaml:"cooldown"`
+}
+
+type HiveAddress struct {
+	Host string
+	Port int
+}
+
+// Addresses returns the metastores listed in uris, like hive.metastore.uris, or url and port when empty.
+func (h Hive) Addresses() ([]HiveAddress, error) {
+	if len(h.Uris) == 0 {
+		return []HiveAddress{{Host: h.Url, Port: h.Port}}, nil
+	}
+	addresses := make([]HiveAddress, 0, len(h.Uris))
+	for _, uri := range h.Uris {
+		parsed, err := url.Parse(uri)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hive uri %s: %w", uri, err)
+		}
+		port, err := strconv.Atoi(parsed.Port())
+		if parsed.Scheme != "thrift" || parsed.Hostname() == "" || err != nil {
+			return nil, fmt.Errorf("invalid hive uri %s, expected thrift://host:port", uri)
+		}
+		addresses = append(addresses, HiveAddress{Host: parsed.Hostname(), Port: port})
+	}
+	return addresses, nil
+}
+
+// HivePool reuses hive connections, enabled when max_size is set.
+type HivePool struct {
+	MaxSize         int           `yaml:"max_size"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout"`
+	BorrowTimeout   time.Duration `yaml:"borrow_timeout"`
+	HealthCheckIdle time.Duration `yaml:"health_check_idle"`
+}
+
+// Glue targets the catalog of the catalog_id account, the caller's own when empty,
+// assuming the assume_role role for glue and its s3 data when set.
+type Glue struct {
+	// Region defaults to the aws region.
+	Region     string     `yaml:"region"`
+	CatalogId  string     `yaml:"catalog_id"`
+	AssumeRole AssumeRole `yaml:"assume_role"`
+}
+
+type AssumeRole struct {
+	RoleArn     string `yaml:"role_arn"`
+	ExternalId  string `yaml:"external_id"`
+	SessionName string `yaml:"session_name"`
+}
+
+// IcebergRest is the iceberg REST catalog, enabled when its uri is set.
+type IcebergRest struct {
+	Uri     string        `yaml:"uri"`
+	Prefix  string        `yaml:"prefix"`
+	Token   string        `yaml:"token"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// HiveDb reads the databases of catalog on hive 3 metastore databases, hive by default.
+type HiveDb struct {
+	Catalog string `yaml:"catalog"`
+}
+
+// File is a directory of table definitions, e.g. a git checkout, written as yaml or json.
+type File struct {
+	Path   string `yaml:"path"`
+	Format string `yaml:"format"`
+}
+
+type TableParameters struct {
+	Exclude []string `yaml:"exclude"`
+}
+
+type Iceberg struct {
+	PreventRollback bool `yaml:"prevent_rollback"`
 }
 
 type Prometheus struct {
@@ -39,6 +188,31 @@ type Db struct {
 	Driver   string `yaml:"driver"`
 }
 
+// ConnectionString returns the DSN of the database in the format expected by its driver.
 func (p *Db) ConnectionString() string {
+	if p.Driver == "mysql" {
+		return p.mysqlConnectionString()
+	}
 	return fmt.Sprintf("%s://%s:%s@%s:%d/%s?sslmode=%s", p.Driver, p.User, url.QueryEscape(p.Password), p.Host, p.Port, p.Database, p.SSLMode)
 }
+
+// mysqlTLS maps the postgres ssl modes to the tls values of the mysql driver.
+var mysqlTLS = map[string]string{
+	"disable":     "false",
+	"allow":       "preferred",
+	"prefer":      "preferred",
+	"require":     "skip-verify",
+	"verify-ca":   "true",
+	"verify-full": "true",
+}
+
+func (p *Db) mysqlConnectionString() string {
+	conf := mysql.NewConfig()
+	conf.User = p.User
+	conf.Passwd = p.Password
+	conf.Net = "tcp"
+	conf.Addr = net.JoinHostPort(p.Host, strconv.Itoa(p.Port))
+	conf.DBName = p.Database
+	conf.TLSConfig = mysqlTLS[p.SSLMode]
+	return conf.FormatDSN()
+}
@@ -0,0 +1,128 @@
+package iceberg
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+)
+
+type FileReader interface {
+	Read(ctx context.Context, location string) ([]byte, error)
+	List(ctx context.Context, location string) ([]string, error)
+}
+
+type S3Client interface {
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+}
+
+// gzipMagic are the first bytes of gzip compressed files.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// LocationFileReader reads and lists files on s3 (s3:// and s3a://) and on the local filesystem (file://),
+// gzip compressed files being recognized by their content, whatever their extension
+// (.metadata.json.gz or .gz.metadata.json as written by iceberg).
+type LocationFileReader struct {
+	s3Client S3Client
+}
+
+func NewLocationFileReader(s3Client S3Client) *LocationFileReader {
+	return &LocationFileReader{s3Client: s3Client}
+}
+
+func (l *LocationFileReader) Read(ctx context.Context, location string) ([]byte, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+	var reader io.ReadCloser
+	switch u.Scheme {
+	case "s3", "s3a":
+		if l.s3Client == nil {
+			return nil, fmt.Errorf("no s3 client to read %s", location)
+		}
+		key := strings.TrimPrefix(u.Path, "/")
+		out, err := l.s3Client.GetObject(ctx, &s3.GetObjectInput{
+			Bucket: &u.Host,
+			Key:    &key,
+		})
+		if err != nil {
+			return nil, err
+		}
+		reader = out.Body
+	case "file":
+		reader, err = os.Open(u.Path)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported location %s", location)
+	}
+	defer reader.Close()
+	buffered := bufio.NewReader(reader)
+	if magic, err := buffered.Peek(len(gzipMagic)); err == nil && bytes.Equal(magic, gzipMagic) {
+		gzipReader, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+		defer gzipReader.Close()
+		return io.ReadAll(gzipReader)
+	}
+	return io.ReadAll(buffered)
+}
+
+// List returns the locations of the files directly under the given location.
+func (l *LocationFileReader) List(ctx context.Context, location string) ([]string, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+	dir := strings.TrimSuffix(location, "/")
+	files := make([]string, 0)
+	switch u.Scheme {
+	case "s3", "s3a":
+		if l.s3Client == nil {
+			return nil, fmt.Errorf("no s3 client to list %s", location)
+		}
+		prefix := strings.TrimSuffix(strings.TrimPrefix(u.Path, "/"), "/") + "/"
+		isTruncated := true
+		var token *string
+		for isTruncated {
+			objs, err := l.s3Client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+				Bucket:            &u.Host,
+				ContinuationToken: token,
+				Prefix:            &prefix,
+				Delimiter:         aws.String("/"),
+			})
+			if err != nil {
+				return nil, err
+			}
+			token = objs.NextContinuationToken
+			isTruncated = objs.IsTruncated
+			for _, obj := range objs.Contents {
+				files = append(files, fmt.Sprintf("%s/%s", dir, strings.TrimPrefix(aws.ToString(obj.Key), prefix)))
+			}
+		}
+	case "file":
+		entries, err := os.ReadDir(u.Path)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				files = append(files, fmt.Sprintf("%s/%s", dir, entry.Name()))
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported location %s", location)
+	}
+	return files, nil
+}
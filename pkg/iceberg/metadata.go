@@ -0,0 +1,166 @@
+package iceberg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/the-Data-Appeal-Company/metaman/pkg/model"
+	"path"
+	"strconv"
+	"strings"
+)
+
+type TableMetadata struct {
+	FormatVersion   int               `json:"format-version"`
+	Location        string            `json:"location"`
+	CurrentSchemaID int               `json:"current-schema-id"`
+	Schemas         []Schema          `json:"schemas"`
+	Schema          *Schema           `json:"schema"`
+	Properties      map[string]string `json:"properties"`
+}
+
+type Schema struct {
+	SchemaID int     `json:"schema-id"`
+	Fields   []Field `json:"fields"`
+}
+
+type Field struct {
+	ID       int             `json:"id"`
+	Name     string          `json:"name"`
+	Required bool            `json:"required"`
+	Type     json.RawMessage `json:"type"`
+	Doc      string          `json:"doc"`
+}
+
+// CurrentSchema returns the schema in use, format v1 metadata may only have the single schema field.
+func (m TableMetadata) CurrentSchema() (Schema, error) {
+	for _, schema := range m.Schemas {
+		if schema.SchemaID == m.CurrentSchemaID {
+			return schema, nil
+		}
+	}
+	if m.Schema != nil {
+		return *m.Schema, nil
+	}
+	return Schema{}, fmt.Errorf("current schema %d not found", m.CurrentSchemaID)
+}
+
+type Reader interface {
+	ReadMetadata(location string) (TableMetadata, error)
+	LatestMetadataLocation(tableLocation string) (string, error)
+}
+
+type MetadataReader struct {
+	files FileReader
+}
+
+func NewMetadataReader(files FileReader) *MetadataReader {
+	return &MetadataReader{files: files}
+}
+
+func (r *MetadataReader) ReadMetadata(location string) (TableMetadata, error) {
+	data, err := r.files.Read(context.Background(), location)
+	if err != nil {
+		return TableMetadata{}, err
+	}
+	var metadata TableMetadata
+	err = json.Unmarshal(data, &metadata)
+	if err != nil {
+		return TableMetadata{}, fmt.Errorf("invalid iceberg metadata %s: %s", location, err.Error())
+	}
+	return metadata, nil
+}
+
+// ApplySchema fills the table columns with the current schema of the iceberg table
+// and adds its properties to the table parameters, the ones already set taking precedence.
+// Iceberg partitioning is hidden: partition source columns are regular columns and
+// the table has no metastore partition keys.
+func ApplySchema(table *model.TableInfo, metadata TableMetadata) error {
+	schema, err := metadata.CurrentSchema()
+	if err != nil {
+		return err
+	}
+	columns := make([]model.Column, len(schema.Fields))
+	for i, field := range schema.Fields {
+		columnType, err := mapType(field.Type)
+		if err != nil {
+			return fmt.Errorf("column %s: %s", field.Name, err.Error())
+		}
+		columns[i] = model.Column{
+			Name:    field.Name,
+			Type:    columnType,
+			Comment: field.Doc,
+		}
+	}
+	table.Columns = columns
+	if len(metadata.Properties) > 0 {
+		parameters := make(map[string]string, len(metadata.Properties)+len(table.Parameters))
+		for k, v := range metadata.Properties {
+			parameters[k] = v
+		}
+		for k, v := range table.Parameters {
+			parameters[k] = v
+		}
+		table.Parameters = parameters
+	}
+	return nil
+}
+
+// IsMetadataFile tells whether the location is a metadata file of an iceberg table, gzip compressed or not.
+func IsMetadataFile(location string) bool {
+	return strings.Contains(location, "/metadata/") && (strings.HasSuffix(location, ".json") || strings.HasSuffix(location, ".json.gz"))
+}
+
+// TableRoot returns the root location of the iceberg table of a metadata file location,
+// locations outside a metadata directory being returned as they are.
+func TableRoot(location string) string {
+	if strings.Contains(location, "/metadata/") {
+		return location[0:strings.LastIndex(location, "/metadata/")]
+	}
+	return location
+}
+
+const versionHintFile = "version-hint.text"
+
+// LatestMetadataLocation finds the current metadata file of the iceberg table at tableLocation,
+// from the version hint of hadoop tables when present, or as the metadata file with the highest version.
+// A version hint pointing to a missing metadata file is an error, as the table state is uncertain.
+func (r *MetadataReader) LatestMetadataLocation(tableLocation string) (string, error) {
+	ctx := context.Background()
+	metadataDir := strings.TrimSuffix(tableLocation, "/") + "/metadata"
+	files, err := r.files.List(ctx, metadataDir)
+	if err != nil {
+		return "", err
+	}
+	latest, latestVersion := "", -1
+	for _, file := range files {
+		version, ok := model.IcebergMetadataVersion(file)
+		if ok && version > latestVersion {
+			latest, latestVersion = file, version
+		}
+	}
+	for _, file := range files {
+		if path.Base(file) != versionHintFile {
+			continue
+		}
+		hint, err := r.files.Read(ctx, file)
+		if err != nil {
+			return "", err
+		}
+		version, err := strconv.Atoi(strings.TrimSpace(string(hint)))
+		if err != nil {
+			return "", fmt.Errorf("invalid version hint %s: %s", file, err.Error())
+		}
+		for _, candidate := range files {
+			candidateVersion, ok := model.IcebergMetadataVersion(candidate)
+			if ok && candidateVersion == version && strings.HasPrefix(path.Base(candidate), "v") {
+				return candidate, nil
+			}
+		}
+		return "", fmt.Errorf("version hint %s points to the missing metadata version %d", file, version)
+	}
+	if latest == "" {
+		return "", fmt.Errorf("no iceberg metadata found in %s", metadataDir)
+	}
+	return latest, nil
+}
@@ -0,0 +1,188 @@
+package iceberg
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/require"
+	"github.com/the-Data-Appeal-Company/metaman/pkg/model"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testMetadataFile = "00001-8051da97-485b-4715-b22f-6302b46c752e.metadata.json"
+
+func testMetadataLocation(t *testing.T) string {
+	path, err := filepath.Abs(filepath.Join("testdata", testMetadataFile))
+	require.NoError(t, err)
+	return fmt.Sprintf("file://%s", path)
+}
+
+func TestMetadataReader_ReadMetadata(t *testing.T) {
+	r := NewMetadataReader(NewLocationFileReader(nil))
+	metadata, err := r.ReadMetadata(testMetadataLocation(t))
+	require.NoError(t, err)
+	require.Equal(t, 2, metadata.FormatVersion)
+	require.Equal(t, "s3://bucket/pls/events", metadata.Location)
+	schema, err := metadata.CurrentSchema()
+	require.NoError(t, err)
+	require.Equal(t, 1, schema.SchemaID)
+
+	_, err = r.ReadMetadata("file:///not/existing.metadata.json")
+	require.Error(t, err)
+	_, err = r.ReadMetadata("s3://bucket/pls/events/metadata/" + testMetadataFile)
+	require.Error(t, err)
+}
+
+func TestMetadataReader_ReadMetadataGzip(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", testMetadataFile))
+	require.NoError(t, err)
+	dir := t.TempDir()
+	for _, name := range []string{"00001-events.gz.metadata.json", "00001-events.metadata.json.gz"} {
+		t.Run(name, func(t *testing.T) {
+			var compressed bytes.Buffer
+			writer := gzip.NewWriter(&compressed)
+			_, err := writer.Write(data)
+			require.NoError(t, err)
+			require.NoError(t, writer.Close())
+			file := filepath.Join(dir, name)
+			require.NoError(t, os.WriteFile(file, compressed.Bytes(), 0644))
+
+			metadata, err := NewMetadataReader(NewLocationFileReader(nil)).ReadMetadata("file://" + file)
+			require.NoError(t, err)
+			require.Equal(t, "s3://bucket/pls/events", metadata.Location)
+		})
+	}
+}
+
+func TestApplySchema(t *testing.T) {
+	metadata, err := NewMetadataReader(NewLocationFileReader(nil)).ReadMetadata(testMetadataLocation(t))
+	require.NoError(t, err)
+	table := model.TableInfo{
+		Name:       "events",
+		Format:     model.ICEBERG,
+		Parameters: map[string]string{"owner": "pls"},
+	}
+	require.NoError(t, ApplySchema(&table, metadata))
+
+	types := make([]string, len(table.Columns))
+	for i, column := range table.Columns {
+		types[i] = fmt.Sprintf("%s %s", column.Name, model.UnmapColumnType(column.Type))
+	}
+	require.Equal(t, []string{
+		"id bigint",
+		"ts timestamp",
+		"amount decimal(10,2)",
+		"tags array<string>",
+		"attributes map<string,int>",
+		"device struct<uuid:string,hash:binary,active:boolean>",
+	}, types)
+	require.Equal(t, "event id", table.Columns[0].Comment)
+	require.Empty(t, table.Partitions)
+	require.Equal(t, map[string]string{"write.format.default": "parquet", "owner": "pls"}, table.Parameters)
+}
+
+func TestApplySchemaUnsupportedType(t *testing.T) {
+	metadata := TableMetadata{
+		Schemas: []Schema{{Fields: []Field{{Name: "geo", Type: []byte(`"geometry"`)}}}},
+	}
+	require.Error(t, ApplySchema(&model.TableInfo{}, metadata))
+	require.Error(t, ApplySchema(&model.TableInfo{}, TableMetadata{CurrentSchemaID: 3}))
+}
+
+func writeMetadataFiles(t *testing.T, files ...string) string {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "metadata"), 0o755))
+	for _, file := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(root, "metadata", file), []byte("3\n"), 0o644))
+	}
+	return root
+}
+
+func TestMetadataReader_LatestMetadataLocation(t *testing.T) {
+	r := NewMetadataReader(NewLocationFileReader(nil))
+
+	root := writeMetadataFiles(t, "00001-aaaa.metadata.json", "00010-bbbb.metadata.json", "00002-cccc.metadata.json", "snap-1-1-dddd.avro")
+	got, err := r.LatestMetadataLocation("file://" + root + "/")
+	require.NoError(t, err)
+	require.Equal(t, "file://"+root+"/metadata/00010-bbbb.metadata.json", got)
+
+	root = writeMetadataFiles(t, "v2.metadata.json", "v3.metadata.json", "v4.metadata.json", "version-hint.text")
+	got, err = r.LatestMetadataLocation("file://" + root)
+	require.NoError(t, err)
+	require.Equal(t, "file://"+root+"/metadata/v3.metadata.json", got)
+
+	root = writeMetadataFiles(t, "v2.gz.metadata.json", "v3.gz.metadata.json", "version-hint.text")
+	got, err = r.LatestMetadataLocation("file://" + root)
+	require.NoError(t, err)
+	require.Equal(t, "file://"+root+"/metadata/v3.gz.metadata.json", got)
+
+	root = writeMetadataFiles(t, "v2.metadata.json", "v4.metadata.json", "version-hint.text")
+	_, err = r.LatestMetadataLocation("file://" + root)
+	require.Error(t, err)
+
+	root = writeMetadataFiles(t, "snap-1-1-dddd.avro")
+	_, err = r.LatestMetadataLocation("file://" + root)
+	require.Error(t, err)
+}
+
+type S3Mock struct {
+	listCalls []*s3.ListObjectsV2Input
+}
+
+func (s *S3Mock) GetObject(_ context.Context, params *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	return nil, fmt.Errorf("not found: %s", *params.Key)
+}
+
+func (s *S3Mock) ListObjectsV2(_ context.Context, params *s3.ListObjectsV2Input, _ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	s.listCalls = append(s.listCalls, params)
+	if params.ContinuationToken == nil {
+		return &s3.ListObjectsV2Output{
+			Contents:              []types.Object{{Key: aws.String("db/table/metadata/00001-aaaa.metadata.json")}},
+			IsTruncated:           true,
+			NextContinuationToken: aws.String("token"),
+		}, nil
+	}
+	return &s3.ListObjectsV2Output{
+		Contents: []types.Object{{Key: aws.String("db/table/metadata/00002-bbbb.metadata.json")}},
+	}, nil
+}
+
+func TestMetadataReader_LatestMetadataLocationS3(t *testing.T) {
+	mock := &S3Mock{}
+	r := NewMetadataReader(NewLocationFileReader(mock))
+	got, err := r.LatestMetadataLocation("s3://bucket/db/table")
+	require.NoError(t, err)
+	require.Equal(t, "s3://bucket/db/table/metadata/00002-bbbb.metadata.json", got)
+	require.Len(t, mock.listCalls, 2)
+	require.Equal(t, "db/table/metadata/", *mock.listCalls[0].Prefix)
+}
+
+func TestIsMetadataFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		location string
+		want     bool
+	}{
+		{name: "shouldMetadataFile", location: "s3://bucket/table/metadata/00001-aaaa.metadata.json", want: true},
+		{name: "shouldGzipMetadataFile", location: "s3://bucket/table/metadata/00001-aaaa.metadata.json.gz", want: true},
+		{name: "shouldGzipInfixMetadataFile", location: "s3://bucket/table/metadata/00001-aaaa.gz.metadata.json", want: true},
+		{name: "shouldNotTableRoot", location: "s3://bucket/table", want: false},
+		{name: "shouldNotManifest", location: "s3://bucket/table/metadata/snap-1-1-dddd.avro", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, IsMetadataFile(tt.location))
+		})
+	}
+}
+
+func TestTableRoot(t *testing.T) {
+	require.Equal(t, "s3://bucket/table", TableRoot("s3://bucket/table/metadata/00001-aaaa.metadata.json.gz"))
+	require.Equal(t, "s3://bucket/table", TableRoot("s3://bucket/table"))
+}
@@ -0,0 +1,97 @@
+package iceberg
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/the-Data-Appeal-Company/metaman/pkg/model"
+	"regexp"
+	"strconv"
+)
+
+type nestedType struct {
+	Type    string          `json:"type"`
+	Fields  []Field         `json:"fields"`
+	Element json.RawMessage `json:"element"`
+	Key     json.RawMessage `json:"key"`
+	Value   json.RawMessage `json:"value"`
+}
+
+var (
+	decimalType = regexp.MustCompile(`^decimal\(\s*(\d+)\s*,\s*(\d+)\s*\)$`)
+	fixedType   = regexp.MustCompile(`^fixed\[\d+\]$`)
+)
+
+// mapType translates an iceberg type, either a primitive type name or a nested
+// struct, list or map object, to the corresponding hive type.
+func mapType(raw json.RawMessage) (model.ColumnType, error) {
+	var primitive string
+	if err := json.Unmarshal(raw, &primitive); err == nil {
+		return mapPrimitiveType(primitive)
+	}
+	var nested nestedType
+	if err := json.Unmarshal(raw, &nested); err != nil {
+		return model.ColumnType{}, fmt.Errorf("invalid type %s", string(raw))
+	}
+	switch nested.Type {
+	case "struct":
+		fields := make([]model.StructField, len(nested.Fields))
+		for i, field := range nested.Fields {
+			fieldType, err := mapType(field.Type)
+			if err != nil {
+				return model.ColumnType{}, err
+			}
+			fields[i] = model.StructField{Name: field.Name, Type: fieldType}
+		}
+		return model.ColumnType{SqlType: model.STRUCT, Fields: fields}, nil
+	case "list":
+		element, err := mapType(nested.Element)
+		if err != nil {
+			return model.ColumnType{}, err
+		}
+		return model.ColumnType{SqlType: model.ARRAY, Element: &element}, nil
+	case "map":
+		key, err := mapType(nested.Key)
+		if err != nil {
+			return model.ColumnType{}, err
+		}
+		value, err := mapType(nested.Value)
+		if err != nil {
+			return model.ColumnType{}, err
+		}
+		return model.ColumnType{SqlType: model.MAP, Key: &key, Value: &value}, nil
+	default:
+		return model.ColumnType{}, fmt.Errorf("unsupported type %s", nested.Type)
+	}
+}
+
+func mapPrimitiveType(t string) (model.ColumnType, error) {
+	switch t {
+	case "boolean":
+		return model.ColumnType{SqlType: model.BOOLEAN}, nil
+	case "int":
+		return model.ColumnType{SqlType: model.INTEGER}, nil
+	case "long":
+		return model.ColumnType{SqlType: model.BIGINT}, nil
+	case "float":
+		return model.ColumnType{SqlType: model.FLOAT}, nil
+	case "double":
+		return model.ColumnType{SqlType: model.DOUBLE}, nil
+	case "date":
+		return model.ColumnType{SqlType: model.DATE}, nil
+	case "timestamp", "timestamptz", "timestamp_ns", "timestamptz_ns":
+		return model.ColumnType{SqlType: model.TIMESTAMP}, nil
+	case "string", "uuid", "time":
+		return model.ColumnType{SqlType: model.STRING}, nil
+	case "binary":
+		return model.ColumnType{SqlType: model.BINARY}, nil
+	}
+	if fixedType.MatchString(t) {
+		return model.ColumnType{SqlType: model.BINARY}, nil
+	}
+	if match := decimalType.FindStringSubmatch(t); match != nil {
+		precision, _ := strconv.Atoi(match[1])
+		scale, _ := strconv.Atoi(match[2])
+		return model.ColumnType{SqlType: model.DECIMAL, Precision: precision, Scale: scale}, nil
+	}
+	return model.ColumnType{}, fmt.Errorf("unsupported type %s", t)
+}
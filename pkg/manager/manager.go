@@ -4,22 +4,34 @@ import (
 	"fmt"
 	"github.com/hashicorp/go-multierror"
 	"github.com/sirupsen/logrus"
+	"github.com/the-Data-Appeal-Company/metaman/pkg/iceberg"
 	"github.com/the-Data-Appeal-Company/metaman/pkg/metastore"
 	"github.com/the-Data-Appeal-Company/metaman/pkg/model"
+	"path"
+	"reflect"
+	"strings"
 )
 
 type Manager interface {
 	Drop(metastore metastore.MetastoreCode, tables []model.DropArg) []error
 	Create(metastore []metastore.MetastoreCode, tables []model.DatabaseTables) error
-	Sync(sourceMetastore metastore.MetastoreCode, targetMetastore metastore.MetastoreCode, dbName string, tables []string, delete bool) error
+	Sync(sourceMetastore metastore.MetastoreCode, targetMetastore metastore.MetastoreCode, dbName string, tables []string, delete bool, createDatabase bool) error
+	SyncDatabases(sourceMetastore metastore.MetastoreCode, targetMetastore metastore.MetastoreCode, dbPattern string, delete bool, createDatabase bool) ([]model.SyncResult, error)
+	GetDatabases(metastore metastore.MetastoreCode) ([]string, error)
+	CreateDatabases(metastores []metastore.MetastoreCode, databases []model.Database) error
+	DropDatabases(metastore metastore.MetastoreCode, dbNames []string, cascade bool) error
+	RegisterIceberg(metastores []metastore.MetastoreCode, dbName string, tableName string, tableLocation string) error
+	Metastores() []metastore.MetastoreCode
 }
 
 type HiveGlueManager struct {
-	pool metastore.Pool
+	pool                   metastore.Pool
+	icebergReader          iceberg.Reader
+	preventIcebergRollback bool
 }
 
-func NewHiveGlueManager(pool metastore.Pool) *HiveGlueManager {
-	return &HiveGlueManager{pool: pool}
+func NewHiveGlueManager(pool metastore.Pool, icebergReader iceberg.Reader, preventIcebergRollback bool) *HiveGlueManager {
+	return &HiveGlueManager{pool: pool, icebergReader: icebergReader, preventIcebergRollback: preventIcebergRollback}
 }
 
 func (h *HiveGlueManager) Drop(metastore metastore.MetastoreCode, tables []model.DropArg) []error {
@@ -41,7 +53,7 @@ func (h *HiveGlueManager) Drop(metastore metastore.MetastoreCode, tables []model
 }
 
 func (h *HiveGlueManager) Create(metastores []metastore.MetastoreCode, tables []model.DatabaseTables) error {
-	var result error
+	tables, result := h.withIcebergSchemas(tables)
 	for _, code := range metastores {
 		meta, err := h.pool.Get(code)
 		if err != nil {
@@ -62,7 +74,105 @@ func (h *HiveGlueManager) Create(metastores []metastore.MetastoreCode, tables []
 	return result
 }
 
-func (h *HiveGlueManager) Sync(sourceMetastore metastore.MetastoreCode, targetMetastore metastore.MetastoreCode, dbName string, tables []string, delete bool) error {
+// RegisterIceberg creates the iceberg table rooted at tableLocation in the given metastores,
+// pointing it to its latest metadata file.
+func (h *HiveGlueManager) RegisterIceberg(metastores []metastore.MetastoreCode, dbName string, tableName string, tableLocation string) error {
+	if h.icebergReader == nil {
+		return fmt.Errorf("iceberg metadata reader not configured")
+	}
+	location, err := h.icebergReader.LatestMetadataLocation(tableLocation)
+	if err != nil {
+		return err
+	}
+	logrus.Infof("register iceberg table: %s with metadata: %s", tableName, location)
+	return h.Create(metastores, []model.DatabaseTables{
+		{
+			Db: dbName,
+			Tables: []model.TableInfo{
+				{
+					Name:             tableName,
+					MetadataLocation: location,
+					Format:           model.ICEBERG,
+				},
+			},
+		},
+	})
+}
+
+// withIcebergSchemas fills the columns of iceberg tables defined without them
+// reading the schema from their metadata file, tables that cannot be read are skipped.
+func (h *HiveGlueManager) withIcebergSchemas(tables []model.DatabaseTables) ([]model.DatabaseTables, error) {
+	var result error
+	filled := make([]model.DatabaseTables, len(tables))
+	for i, dbTab := range tables {
+		filled[i] = model.DatabaseTables{Db: dbTab.Db, Tables: make([]model.TableInfo, 0, len(dbTab.Tables))}
+		for _, tab := range dbTab.Tables {
+			if tab.Format == model.ICEBERG && len(tab.Columns) == 0 && h.icebergReader != nil {
+				logrus.Infof("read iceberg schema of table: %s from: %s", tab.Name, tab.MetadataLocation)
+				metadata, err := h.icebergReader.ReadMetadata(tab.MetadataLocation)
+				if err == nil {
+					err = iceberg.ApplySchema(&tab, metadata)
+				}
+				if err != nil {
+					result = multierror.Append(result, fmt.Errorf("db: %s, table: %s, error: %s", dbTab.Db, tab.Name, err.Error()))
+					continue
+				}
+			}
+			filled[i].Tables = append(filled[i].Tables, tab)
+		}
+	}
+	return filled, result
+}
+
+// Metastores returns the names of the configured metastores.
+func (h *HiveGlueManager) Metastores() []metastore.MetastoreCode {
+	return h.pool.Codes()
+}
+
+func (h *HiveGlueManager) GetDatabases(metastore metastore.MetastoreCode) ([]string, error) {
+	meta, err := h.pool.Get(metastore)
+	if err != nil {
+		return nil, err
+	}
+	return meta.GetDatabases()
+}
+
+func (h *HiveGlueManager) CreateDatabases(metastores []metastore.MetastoreCode, databases []model.Database) error {
+	var result error
+	for _, code := range metastores {
+		meta, err := h.pool.Get(code)
+		if err != nil {
+			result = multierror.Append(result, err)
+			continue
+		}
+		for _, database := range databases {
+			logrus.Infof("create database: %s", database.Name)
+			err := meta.CreateDatabase(database)
+			if err != nil {
+				result = multierror.Append(result, err)
+			}
+		}
+	}
+	return result
+}
+
+func (h *HiveGlueManager) DropDatabases(metastore metastore.MetastoreCode, dbNames []string, cascade bool) error {
+	meta, err := h.pool.Get(metastore)
+	if err != nil {
+		return err
+	}
+	var result error
+	for _, dbName := range dbNames {
+		logrus.Infof("drop database: %s", dbName)
+		err := meta.DropDatabase(dbName, cascade)
+		if err != nil {
+			result = multierror.Append(result, fmt.Errorf("db: %s, error: %s", dbName, err.Error()))
+		}
+	}
+	return result
+}
+
+func (h *HiveGlueManager) Sync(sourceMetastore metastore.MetastoreCode, targetMetastore metastore.MetastoreCode, dbName string, tables []string, delete bool, createDatabase bool) error {
 	source, err := h.pool.Get(sourceMetastore)
 	if err != nil {
 		return err
@@ -72,6 +182,12 @@ func (h *HiveGlueManager) Sync(sourceMetastore metastore.MetastoreCode, targetMe
 		return err
 	}
 	logrus.Infof("syncing tables from: %s to: %s, db: %s", sourceMetastore, targetMetastore, dbName)
+	if createDatabase {
+		err = syncDatabase(source, target, dbName)
+		if err != nil {
+			return err
+		}
+	}
 	sourceTables := tables
 	if len(tables) == 0 {
 		sourceTables, err = source.GetTables(dbName)
@@ -83,8 +199,8 @@ func (h *HiveGlueManager) Sync(sourceMetastore metastore.MetastoreCode, targetMe
 	if err != nil {
 		return err
 	}
-	//create
-	result := syncTables(source, target, dbName, sourceTables, targetTables)
+	//create or alter, preloading the whole database only when syncing all of its tables
+	result := h.syncTables(source, target, dbName, sourceTables, targetTables, len(tables) == 0)
 	//drop
 	if delete {
 		for _, targetTable := range targetTables {
@@ -100,30 +216,286 @@ func (h *HiveGlueManager) Sync(sourceMetastore metastore.MetastoreCode, targetMe
 	return result
 }
 
-func syncTables(source metastore.Metastore, target metastore.Metastore, dbName string, sourceTables, targetTables []string) error {
+// SyncDatabases syncs every source database whose name matches dbPattern
+// (shell glob syntax, "*" for all databases) and reports the outcome per database.
+func (h *HiveGlueManager) SyncDatabases(sourceMetastore metastore.MetastoreCode, targetMetastore metastore.MetastoreCode, dbPattern string, delete bool, createDatabase bool) ([]model.SyncResult, error) {
+	source, err := h.pool.Get(sourceMetastore)
+	if err != nil {
+		return nil, err
+	}
+	databases, err := source.GetDatabases()
+	if err != nil {
+		return nil, err
+	}
+	dbNames, err := matchDatabases(databases, dbPattern)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Infof("syncing %d databases matching: %s from: %s to: %s", len(dbNames), dbPattern, sourceMetastore, targetMetastore)
 	var result error
+	results := make([]model.SyncResult, len(dbNames))
+	for i, dbName := range dbNames {
+		results[i] = model.SyncResult{Db: dbName}
+		err := h.Sync(sourceMetastore, targetMetastore, dbName, nil, delete, createDatabase)
+		if err != nil {
+			results[i].Error = err.Error()
+			result = multierror.Append(result, fmt.Errorf("db: %s, error: %s", dbName, err.Error()))
+		}
+	}
+	return results, result
+}
+
+func matchDatabases(databases []string, dbPattern string) ([]string, error) {
+	if _, err := path.Match(dbPattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid database pattern %s: %s", dbPattern, err.Error())
+	}
+	matches := make([]string, 0)
+	for _, database := range databases {
+		match, err := path.Match(dbPattern, database)
+		if err != nil {
+			return nil, err
+		}
+		if match {
+			matches = append(matches, database)
+		}
+	}
+	return matches, nil
+}
+
+func syncDatabase(source metastore.Metastore, target metastore.Metastore, dbName string) error {
+	targetDatabases, err := target.GetDatabases()
+	if err != nil {
+		return err
+	}
+	if tableExists(dbName, targetDatabases) {
+		return nil
+	}
+	database, err := source.GetDatabaseInfo(dbName)
+	if err != nil {
+		return err
+	}
+	logrus.Infof("create database: %s", dbName)
+	return target.CreateDatabase(database)
+}
+
+func (h *HiveGlueManager) syncTables(source metastore.Metastore, target metastore.Metastore, dbName string, sourceTables, targetTables []string, preload bool) error {
+	var result error
+	var preloaded map[string]model.TableInfo
+	if preload {
+		preloaded = preloadTables(source, dbName)
+	}
 	for _, sourceTable := range sourceTables {
-		if !tableExists(sourceTable, targetTables) {
-			logrus.Infof("create table: %s", sourceTable)
-			err := createTable(source, target, dbName, sourceTable)
-			if err != nil {
-				result = multierror.Append(result, err)
-			}
+		err := h.syncTable(source, target, dbName, sourceTable, preloaded, tableExists(sourceTable, targetTables))
+		if err != nil {
+			result = multierror.Append(result, err)
 		}
 	}
 	return result
 }
 
-func createTable(source metastore.Metastore, target metastore.Metastore, dbName string, sourceTable string) error {
-	info, err := source.GetTableInfo(dbName, sourceTable)
+// preloadTables reads all the tables of the database at once when the source metastore supports it,
+// tables missing from the result are then read one by one.
+func preloadTables(source metastore.Metastore, dbName string) map[string]model.TableInfo {
+	reader, ok := source.(metastore.BulkReader)
+	if !ok {
+		return nil
+	}
+	infos, err := reader.GetTablesInfo(dbName)
+	if err != nil {
+		logrus.Warnf("could not read all tables of db: %s at once: %s", dbName, err.Error())
+	}
+	preloaded := make(map[string]model.TableInfo, len(infos))
+	for _, info := range infos {
+		preloaded[info.Name] = info
+	}
+	return preloaded
+}
+
+func (h *HiveGlueManager) syncTable(source metastore.Metastore, target metastore.Metastore, dbName string, sourceTable string, preloaded map[string]model.TableInfo, exists bool) error {
+	var err error
+	info, ok := preloaded[sourceTable]
+	if !ok {
+		info, err = source.GetTableInfo(dbName, sourceTable)
+		if err != nil {
+			return err
+		}
+	}
+	if !metastore.HoldsFormat(target, info.Format) {
+		logrus.Warnf("table: %s, skipping %s table the target metastore can't hold", sourceTable, info.Format)
+		return nil
+	}
+	if exists {
+		err = h.alterTable(target, dbName, info, comparedProperties(source, target))
+	} else {
+		logrus.Infof("create table: %s", sourceTable)
+		err = target.CreateTable(dbName, info)
+	}
 	if err != nil {
 		return err
 	}
-	err = target.CreateTable(dbName, info)
+	if len(info.Partitions) == 0 {
+		return nil
+	}
+	return syncPartitions(source, target, dbName, sourceTable)
+}
+
+func (h *HiveGlueManager) alterTable(target metastore.Metastore, dbName string, sourceInfo model.TableInfo, properties metastore.Properties) error {
+	targetInfo, err := target.GetTableInfo(dbName, sourceInfo.Name)
 	if err != nil {
 		return err
 	}
-	return nil
+	if !tableChanged(sourceInfo, targetInfo, properties) {
+		return nil
+	}
+	if h.preventIcebergRollback && icebergRollback(sourceInfo, targetInfo) {
+		return fmt.Errorf("table: %s, refusing to roll back iceberg metadata from %s to %s", sourceInfo.Name, targetInfo.MetadataLocation, sourceInfo.MetadataLocation)
+	}
+	logrus.Infof("alter table: %s", sourceInfo.Name)
+	return target.AlterTable(dbName, sourceInfo)
+}
+
+func syncPartitions(source metastore.Metastore, target metastore.Metastore, dbName string, table string) error {
+	sourcePartitions, err := source.GetPartitions(dbName, table)
+	if err != nil {
+		return err
+	}
+	targetPartitions, err := target.GetPartitions(dbName, table)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool, len(targetPartitions))
+	for _, partition := range targetPartitions {
+		existing[partitionKey(partition)] = true
+	}
+	missing := make([]model.Partition, 0)
+	for _, partition := range sourcePartitions {
+		if !existing[partitionKey(partition)] {
+			missing = append(missing, partition)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	logrus.Infof("add %d partitions to table: %s", len(missing), table)
+	return target.AddPartitions(dbName, table, missing)
+}
+
+func partitionKey(partition model.Partition) string {
+	return strings.Join(partition.Values, "/")
+}
+
+// tableChanged compares the source table to the target one, the properties kept by both metastores
+// included: the source description, parameters and serde parameters are to be found in the target,
+// which may have more of them as altering a table keeps the current ones.
+func tableChanged(source, target model.TableInfo, properties metastore.Properties) bool {
+	return source.Format != target.Format ||
+		!reflect.DeepEqual(source.Storage, target.Storage) ||
+		!reflect.DeepEqual(source.Bucketing, target.Bucketing) ||
+		!reflect.DeepEqual(source.Skewed, target.Skewed) ||
+		!columnsEqual(source.Columns, target.Columns, properties.ColumnParameters) ||
+		!columnsEqual(source.Partitions, target.Partitions, properties.ColumnParameters) ||
+		locationChanged(source, target) ||
+		(properties.Description && source.Description != "" && source.Description != target.Description) ||
+		(properties.TableParameters && !parametersIncluded(source.Parameters, target.Parameters, formatParameters(source.Format))) ||
+		(properties.SerdeParameters && !parametersIncluded(source.SerdeParameters, target.SerdeParameters, derivedSerdeParameters))
+}
+
+// comparedProperties returns the table properties kept by both metastores.
+func comparedProperties(source, target metastore.Metastore) metastore.Properties {
+	sourceProperties, targetProperties := metastore.PropertiesOf(source), metastore.PropertiesOf(target)
+	return metastore.Properties{
+		Description:      sourceProperties.Description && targetProperties.Description,
+		TableParameters:  sourceProperties.TableParameters && targetProperties.TableParameters,
+		SerdeParameters:  sourceProperties.SerdeParameters && targetProperties.SerdeParameters,
+		ColumnParameters: sourceProperties.ColumnParameters && targetProperties.ColumnParameters,
+	}
+}
+
+// derivedSerdeParameters are set by the metastores from the table location, with their own s3 scheme.
+var derivedSerdeParameters = map[string]bool{"path": true}
+
+// formatParameters are the table parameters set by the metastores for the format,
+// iceberg ones holding the metadata location with their own s3 scheme.
+func formatParameters(format model.TableFormat) map[string]bool {
+	parameters := map[string]bool{model.IcebergPreviousMetadataLocation: true}
+	for k := range format.Parameters("") {
+		parameters[k] = true
+	}
+	return parameters
+}
+
+// parametersIncluded tells whether the target has all the source parameters, ignored ones apart.
+func parametersIncluded(source, target map[string]string, ignored map[string]bool) bool {
+	for k, v := range source {
+		if ignored[k] {
+			continue
+		}
+		if value, ok := target[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
+
+// icebergRollback tells whether syncing would point the target iceberg table
+// to an older metadata version than the current one.
+func icebergRollback(source, target model.TableInfo) bool {
+	if source.Format != model.ICEBERG || target.Format != model.ICEBERG {
+		return false
+	}
+	sourceVersion, ok := model.IcebergMetadataVersion(source.MetadataLocation)
+	if !ok {
+		return false
+	}
+	targetVersion, ok := model.IcebergMetadataVersion(target.MetadataLocation)
+	if !ok {
+		return false
+	}
+	return sourceVersion < targetVersion
+}
+
+// columnsEqual compares the columns, their parameters only with compareParameters.
+func columnsEqual(source, target []model.Column, compareParameters bool) bool {
+	if len(source) != len(target) {
+		return false
+	}
+	for i := range source {
+		if source[i].Name != target[i].Name ||
+			!columnTypesEqual(source[i].Type, target[i].Type) ||
+			source[i].Comment != target[i].Comment ||
+			(compareParameters && !parametersEqual(source[i].Parameters, target[i].Parameters)) {
+			return false
+		}
+	}
+	return true
+}
+
+// columnTypesEqual compares column types as hive writes them, a string column reading back from hive as varchar(1024).
+func columnTypesEqual(source, target model.ColumnType) bool {
+	return metastore.HiveColumnType(model.UnmapColumnType(source)) == metastore.HiveColumnType(model.UnmapColumnType(target))
+}
+
+func parametersEqual(source, target map[string]string) bool {
+	if len(source) == 0 && len(target) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(source, target)
+}
+
+// locationChanged compares locations read from different metastores:
+// hive uses s3a:// while glue uses s3://, and iceberg tables may be reported
+// either by table root or by metadata file, in which case only roots are compared.
+func locationChanged(source, target model.TableInfo) bool {
+	sourceLocation := normalizeLocation(source.MetadataLocation)
+	targetLocation := normalizeLocation(target.MetadataLocation)
+	if source.Format == model.ICEBERG && !(iceberg.IsMetadataFile(sourceLocation) && iceberg.IsMetadataFile(targetLocation)) {
+		return iceberg.TableRoot(sourceLocation) != iceberg.TableRoot(targetLocation)
+	}
+	return sourceLocation != targetLocation
+}
+
+func normalizeLocation(location string) string {
+	return strings.TrimSuffix(strings.ReplaceAll(location, "s3a://", "s3://"), "/")
 }
 
 func tableExists(sourceTable string, targetTables []string) bool {
@@ -3,8 +3,10 @@ package manager
 import (
 	"fmt"
 	"github.com/stretchr/testify/require"
+	"github.com/the-Data-Appeal-Company/metaman/pkg/iceberg"
 	"github.com/the-Data-Appeal-Company/metaman/pkg/metastore"
 	"github.com/the-Data-Appeal-Company/metaman/pkg/model"
+	"strings"
 	"testing"
 )
 
@@ -12,14 +14,45 @@ type MetastoreMock struct {
 	getTablesCalls       []string
 	getTableInfoCalls    map[string][]string
 	createTableInfoCalls []model.DatabaseTables
+	alterTableInfoCalls  []model.DatabaseTables
 	dropTableInfoCalls   []model.DropArg
 	getTablesOut         []string
+	getTableInfoOut      map[string]model.TableInfo
+	getPartitionsOut     map[string][]model.Partition
+	addPartitionsCalls   map[string][]model.Partition
+	addPartitionsError   error
+	getDatabasesOut      []string
+	createDatabaseCalls  []model.Database
+	dropDatabaseCalls    []string
+	databaseError        map[string]error
 	getTablesError       error
 	getTableInfoError    map[string]error
 	createTableError     map[string]error
+	alterTableError      map[string]error
 	dropTableError       map[string]error
 }
 
+func (m *MetastoreMock) GetDatabases() ([]string, error) {
+	return m.getDatabasesOut, nil
+}
+
+func (m *MetastoreMock) GetDatabaseInfo(dbName string) (model.Database, error) {
+	if err := m.databaseError[dbName]; err != nil {
+		return model.Database{}, err
+	}
+	return getDatabase(dbName), nil
+}
+
+func (m *MetastoreMock) CreateDatabase(database model.Database) error {
+	m.createDatabaseCalls = append(m.createDatabaseCalls, database)
+	return m.databaseError[database.Name]
+}
+
+func (m *MetastoreMock) DropDatabase(dbName string, cascade bool) error {
+	m.dropDatabaseCalls = append(m.dropDatabaseCalls, dbName)
+	return m.databaseError[dbName]
+}
+
 func (m *MetastoreMock) GetTables(dbName string) ([]string, error) {
 	m.getTablesCalls = append(m.getTablesCalls, dbName)
 	if m.getTablesError != nil {
@@ -45,6 +78,9 @@ func (m *MetastoreMock) GetTableInfo(dbName, tableName string) (model.TableInfo,
 	if tableName == "table2" {
 		return model.TableInfo{}, fmt.Errorf("error")
 	}
+	if info, found := m.getTableInfoOut[tableName]; found {
+		return info, nil
+	}
 	return getTableInfo(tableName), nil
 }
 
@@ -74,6 +110,25 @@ func (m *MetastoreMock) CreateTable(dbName string, table model.TableInfo) error
 	return nil
 }
 
+func (m *MetastoreMock) AlterTable(dbName string, table model.TableInfo) error {
+	idx := -1
+	for i, call := range m.alterTableInfoCalls {
+		if call.Db == dbName {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		m.alterTableInfoCalls = append(m.alterTableInfoCalls, model.DatabaseTables{
+			Db:     dbName,
+			Tables: []model.TableInfo{table},
+		})
+	} else {
+		m.alterTableInfoCalls[idx].Tables = append(m.alterTableInfoCalls[idx].Tables, table)
+	}
+	return m.alterTableError[table.Name]
+}
+
 func (m *MetastoreMock) DropTable(dbName string, tableName string, deleteData bool) error {
 	idx := -1
 	for i, call := range m.dropTableInfoCalls {
@@ -104,9 +159,39 @@ func (m *MetastoreMock) DropTable(dbName string, tableName string, deleteData bo
 	return nil
 }
 
+func (m *MetastoreMock) GetPartitions(dbName, tableName string) ([]model.Partition, error) {
+	if dbName != "pls" {
+		return nil, fmt.Errorf("error")
+	}
+	return m.getPartitionsOut[tableName], nil
+}
+
+func (m *MetastoreMock) AddPartitions(dbName, tableName string, partitions []model.Partition) error {
+	if m.addPartitionsCalls == nil {
+		m.addPartitionsCalls = make(map[string][]model.Partition)
+	}
+	m.addPartitionsCalls[tableName] = append(m.addPartitionsCalls[tableName], partitions...)
+	return m.addPartitionsError
+}
+
+func (m *MetastoreMock) DropPartitions(dbName, tableName string, partitions []model.Partition) error {
+	return nil
+}
+
+type BulkMetastoreMock struct {
+	*MetastoreMock
+	getTablesInfoOut   []model.TableInfo
+	getTablesInfoError error
+}
+
+func (m *BulkMetastoreMock) GetTablesInfo(dbName string) ([]model.TableInfo, error) {
+	return m.getTablesInfoOut, m.getTablesInfoError
+}
+
 type MockPool struct {
-	hive *MetastoreMock
-	glue *MetastoreMock
+	hive   *MetastoreMock
+	glue   *MetastoreMock
+	hiveDb *BulkMetastoreMock
 }
 
 func NewMockPool() *MockPool {
@@ -120,9 +205,20 @@ func (m *MockPool) Get(met metastore.MetastoreCode) (metastore.Metastore, error)
 	if met == metastore.GLUE {
 		return m.glue, nil
 	}
+	if met == metastore.HIVE_DB && m.hiveDb != nil {
+		return m.hiveDb, nil
+	}
 	return nil, fmt.Errorf("no such metastore")
 }
 
+func (m *MockPool) Codes() []metastore.MetastoreCode {
+	codes := []metastore.MetastoreCode{metastore.GLUE, metastore.HIVE}
+	if m.hiveDb != nil {
+		codes = append(codes, metastore.HIVE_DB)
+	}
+	return codes
+}
+
 func TestHiveGlueManager_Drop(t *testing.T) {
 	type fields struct {
 		pool metastore.Pool
@@ -211,7 +307,7 @@ func TestHiveGlueManager_Drop(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			h := NewHiveGlueManager(tt.fields.pool)
+			h := NewHiveGlueManager(tt.fields.pool, nil, false)
 			if err := h.Drop(tt.args.metastore, tt.args.tables); (err != nil) != tt.wantErr {
 				t.Errorf("Drop() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -333,28 +429,28 @@ func TestHiveGlueManager_SyncErrorNonExistingSourceMetastore(t *testing.T) {
 	h := &HiveGlueManager{
 		pool: NewMockPool(),
 	}
-	require.Error(t, h.Sync("no", metastore.GLUE, "pls", nil, false))
+	require.Error(t, h.Sync("no", metastore.GLUE, "pls", nil, false, false))
 }
 
 func TestHiveGlueManager_SyncErrorNonExistingTargetMetastore(t *testing.T) {
 	h := &HiveGlueManager{
 		pool: NewMockPool(),
 	}
-	require.Error(t, h.Sync(metastore.GLUE, "no", "pls", nil, false))
+	require.Error(t, h.Sync(metastore.GLUE, "no", "pls", nil, false, false))
 }
 
 func TestHiveGlueManager_SyncErrorWhenSourceGetTablesError(t *testing.T) {
 	h := &HiveGlueManager{
 		pool: NewMockPool(),
 	}
-	require.Error(t, h.Sync(metastore.GLUE, metastore.HIVE, "err", nil, false))
+	require.Error(t, h.Sync(metastore.GLUE, metastore.HIVE, "err", nil, false, false))
 }
 
 func TestHiveGlueManager_SyncErrorWhenTargetGetTablesError(t *testing.T) {
 	h := &HiveGlueManager{
 		pool: &MockPool{hive: &MetastoreMock{}, glue: &MetastoreMock{getTablesError: fmt.Errorf("error")}},
 	}
-	require.Error(t, h.Sync(metastore.HIVE, metastore.GLUE, "pls", nil, false))
+	require.Error(t, h.Sync(metastore.HIVE, metastore.GLUE, "pls", nil, false, false))
 }
 
 func TestHiveGlueManager_SyncNoDifferences(t *testing.T) {
@@ -362,7 +458,7 @@ func TestHiveGlueManager_SyncNoDifferences(t *testing.T) {
 	h := &HiveGlueManager{
 		pool: pool,
 	}
-	require.NoError(t, h.Sync(metastore.HIVE, metastore.GLUE, "pls", nil, false))
+	require.NoError(t, h.Sync(metastore.HIVE, metastore.GLUE, "pls", nil, false, false))
 
 	require.Len(t, pool.glue.createTableInfoCalls, 0)
 }
@@ -372,7 +468,7 @@ func TestHiveGlueManager_SyncOnlyCreate(t *testing.T) {
 	h := &HiveGlueManager{
 		pool: pool,
 	}
-	require.NoError(t, h.Sync(metastore.HIVE, metastore.GLUE, "pls", nil, false))
+	require.NoError(t, h.Sync(metastore.HIVE, metastore.GLUE, "pls", nil, false, false))
 
 	require.Len(t, pool.glue.createTableInfoCalls, 1)
 	require.Equal(t, pool.glue.createTableInfoCalls[0].Db, "pls")
@@ -384,7 +480,7 @@ func TestHiveGlueManager_SyncTargetHasMoreThanSource(t *testing.T) {
 	h := &HiveGlueManager{
 		pool: pool,
 	}
-	require.NoError(t, h.Sync(metastore.GLUE, metastore.HIVE, "pls", nil, false))
+	require.NoError(t, h.Sync(metastore.GLUE, metastore.HIVE, "pls", nil, false, false))
 
 	require.Len(t, pool.glue.createTableInfoCalls, 0)
 }
@@ -396,13 +492,53 @@ func TestHiveGlueManager_SyncContinueOnGetTableInfoError(t *testing.T) {
 	h := &HiveGlueManager{
 		pool: pool,
 	}
-	require.Error(t, h.Sync(metastore.HIVE, metastore.GLUE, "pls", nil, false))
+	require.Error(t, h.Sync(metastore.HIVE, metastore.GLUE, "pls", nil, false, false))
 
 	require.Len(t, pool.glue.createTableInfoCalls, 1)
 	require.Equal(t, pool.glue.createTableInfoCalls[0].Db, "pls")
 	require.Len(t, pool.glue.createTableInfoCalls[0].Tables, 2)
 }
 
+func TestHiveGlueManager_SyncPreloadedTables(t *testing.T) {
+	source := &BulkMetastoreMock{
+		MetastoreMock: &MetastoreMock{getTablesOut: []string{"tab1", "tab2", "tab3"}, getTableInfoError: map[string]error{
+			"tab2": fmt.Errorf("error"),
+		}},
+		getTablesInfoOut: []model.TableInfo{
+			{Name: "tab1", Description: "preloaded"},
+			{Name: "tab3", Description: "preloaded"},
+		},
+		getTablesInfoError: fmt.Errorf("table tab2: invalid column type"),
+	}
+	pool := &MockPool{hiveDb: source, glue: &MetastoreMock{getTablesOut: []string{}}}
+	h := &HiveGlueManager{
+		pool: pool,
+	}
+	require.Error(t, h.Sync(metastore.HIVE_DB, metastore.GLUE, "pls", nil, false, false))
+
+	require.Equal(t, map[string][]string{"pls": {"tab2"}}, source.getTableInfoCalls)
+	require.Len(t, pool.glue.createTableInfoCalls, 1)
+	require.Equal(t, []model.TableInfo{
+		{Name: "tab1", Description: "preloaded"},
+		{Name: "tab3", Description: "preloaded"},
+	}, pool.glue.createTableInfoCalls[0].Tables)
+}
+
+func TestHiveGlueManager_SyncTablesNotPreloaded(t *testing.T) {
+	source := &BulkMetastoreMock{
+		MetastoreMock:    &MetastoreMock{getTableInfoOut: map[string]model.TableInfo{"tab1": {Name: "tab1", Description: "read"}}},
+		getTablesInfoOut: []model.TableInfo{{Name: "tab1", Description: "preloaded"}},
+	}
+	pool := &MockPool{hiveDb: source, glue: &MetastoreMock{getTablesOut: []string{}}}
+	h := &HiveGlueManager{
+		pool: pool,
+	}
+	require.NoError(t, h.Sync(metastore.HIVE_DB, metastore.GLUE, "pls", []string{"tab1"}, false, false))
+
+	require.Equal(t, map[string][]string{"pls": {"tab1"}}, source.getTableInfoCalls)
+	require.Equal(t, []model.TableInfo{{Name: "tab1", Description: "read"}}, pool.glue.createTableInfoCalls[0].Tables)
+}
+
 func TestHiveGlueManager_SyncContinueOnCreateTableError(t *testing.T) {
 	pool := &MockPool{hive: &MetastoreMock{getTablesOut: []string{"tab1", "tab2", "tab3"}}, glue: &MetastoreMock{getTablesOut: []string{}, createTableError: map[string]error{
 		"tab2": fmt.Errorf("error"),
@@ -410,7 +546,7 @@ func TestHiveGlueManager_SyncContinueOnCreateTableError(t *testing.T) {
 	h := &HiveGlueManager{
 		pool: pool,
 	}
-	require.Error(t, h.Sync(metastore.HIVE, metastore.GLUE, "pls", nil, false))
+	require.Error(t, h.Sync(metastore.HIVE, metastore.GLUE, "pls", nil, false, false))
 
 	require.Len(t, pool.glue.createTableInfoCalls, 1)
 	require.Equal(t, pool.glue.createTableInfoCalls[0].Db, "pls")
@@ -424,7 +560,7 @@ func TestHiveGlueManager_SyncContinueOnDropTableError(t *testing.T) {
 	h := &HiveGlueManager{
 		pool: pool,
 	}
-	require.Error(t, h.Sync(metastore.HIVE, metastore.GLUE, "pls", nil, true))
+	require.Error(t, h.Sync(metastore.HIVE, metastore.GLUE, "pls", nil, true, false))
 
 	require.Len(t, pool.glue.dropTableInfoCalls, 1)
 	require.Equal(t, pool.glue.dropTableInfoCalls[0].Db, "pls")
@@ -436,7 +572,7 @@ func TestHiveGlueManager_SyncNoDelete(t *testing.T) {
 	h := &HiveGlueManager{
 		pool: pool,
 	}
-	require.NoError(t, h.Sync(metastore.HIVE, metastore.GLUE, "pls", nil, false))
+	require.NoError(t, h.Sync(metastore.HIVE, metastore.GLUE, "pls", nil, false, false))
 
 	require.Len(t, pool.glue.createTableInfoCalls, 1)
 	require.Equal(t, pool.glue.createTableInfoCalls[0].Db, "pls")
@@ -449,7 +585,7 @@ func TestHiveGlueManager_SyncDelete(t *testing.T) {
 	h := &HiveGlueManager{
 		pool: pool,
 	}
-	require.NoError(t, h.Sync(metastore.HIVE, metastore.GLUE, "pls", nil, true))
+	require.NoError(t, h.Sync(metastore.HIVE, metastore.GLUE, "pls", nil, true, false))
 
 	require.Len(t, pool.glue.createTableInfoCalls, 1)
 	require.Equal(t, pool.glue.createTableInfoCalls[0].Db, "pls")
@@ -460,6 +596,475 @@ func TestHiveGlueManager_SyncDelete(t *testing.T) {
 	require.Len(t, pool.glue.dropTableInfoCalls[0].Tables, 1)
 }
 
+func TestHiveGlueManager_SyncNoAlterWhenUnchanged(t *testing.T) {
+	pool := &MockPool{hive: &MetastoreMock{getTablesOut: []string{"tab1", "tab2"}}, glue: &MetastoreMock{getTablesOut: []string{"tab1", "tab2"}}}
+	h := &HiveGlueManager{
+		pool: pool,
+	}
+	require.NoError(t, h.Sync(metastore.HIVE, metastore.GLUE, "pls", nil, false, false))
+
+	require.Len(t, pool.glue.createTableInfoCalls, 0)
+	require.Len(t, pool.glue.alterTableInfoCalls, 0)
+}
+
+func TestHiveGlueManager_SyncNoAlterWhenOnlyS3SchemeDiffers(t *testing.T) {
+	hiveTable := getTableInfo("tab1")
+	hiveTable.MetadataLocation = "s3a://bucket/tab1/"
+	pool := &MockPool{
+		hive: &MetastoreMock{getTablesOut: []string{"tab1"}, getTableInfoOut: map[string]model.TableInfo{"tab1": hiveTable}},
+		glue: &MetastoreMock{getTablesOut: []string{"tab1"}},
+	}
+	h := &HiveGlueManager{
+		pool: pool,
+	}
+	require.NoError(t, h.Sync(metastore.HIVE, metastore.GLUE, "pls", nil, false, false))
+
+	require.Len(t, pool.glue.alterTableInfoCalls, 0)
+}
+
+func TestHiveGlueManager_SyncAlterChangedTables(t *testing.T) {
+	newColumn := getTableInfo("tab1")
+	newColumn.Columns = append(newColumn.Columns, model.Column{
+		Name: "name",
+		Type: model.ColumnType{SqlType: model.VARCHAR, Length: 200},
+	})
+	newPartition := getTableInfo("tab2")
+	newPartition.Partitions = []model.Column{
+		{
+			Name: "dt",
+			Type: model.ColumnType{SqlType: model.DATE},
+		},
+	}
+	newLocation := getTableInfo("tab3")
+	newLocation.MetadataLocation = "s3://other-bucket/tab3"
+	newFormat := getTableInfo("tab4")
+	newFormat.Format = model.ICEBERG
+	newComment := getTableInfo("tab5")
+	newComment.Columns[0].Comment = "primary key"
+	newDescription := getTableInfo("tab7")
+	newDescription.Description = "visits"
+	newParameter := getTableInfo("tab8")
+	newParameter.Parameters = map[string]string{"avro.schema.literal": "{}"}
+	newSerdeParameter := getTableInfo("tab9")
+	newSerdeParameter.SerdeParameters = map[string]string{"skip.header.line.count": "1"}
+	tables := []string{"tab1", "tab2", "tab3", "tab4", "tab5", "tab6", "tab7", "tab8", "tab9"}
+	pool := &MockPool{
+		hive: &MetastoreMock{getTablesOut: tables, getTableInfoOut: map[string]model.TableInfo{
+			"tab1": newColumn,
+			"tab2": newPartition,
+			"tab3": newLocation,
+			"tab4": newFormat,
+			"tab5": newComment,
+			"tab7": newDescription,
+			"tab8": newParameter,
+			"tab9": newSerdeParameter,
+		}},
+		glue: &MetastoreMock{getTablesOut: tables},
+	}
+	h := &HiveGlueManager{
+		pool: pool,
+	}
+	require.NoError(t, h.Sync(metastore.HIVE, metastore.GLUE, "pls", nil, false, false))
+
+	require.Len(t, pool.glue.createTableInfoCalls, 0)
+	require.Len(t, pool.glue.alterTableInfoCalls, 1)
+	require.Equal(t, "pls", pool.glue.alterTableInfoCalls[0].Db)
+	require.Equal(t, []model.TableInfo{newColumn, newPartition, newLocation, newFormat, newComment, newDescription, newParameter, newSerdeParameter}, pool.glue.alterTableInfoCalls[0].Tables)
+}
+
+func TestHiveGlueManager_SyncNoAlterWhenTargetKeepsMore(t *testing.T) {
+	glueTable := getTableInfo("tab1")
+	glueTable.Columns[0].Type = model.ColumnType{SqlType: model.STRING}
+	glueTable.Parameters = map[string]string{"EXTERNAL": "TRUE", "classification": "parquet"}
+	glueTable.SerdeParameters = map[string]string{"path": "s3://bucket/tab1", "serialization.format": "1"}
+	hiveTable := getTableInfo("tab1")
+	hiveTable.Columns[0].Type = model.ColumnType{SqlType: model.VARCHAR, Length: 1024}
+	hiveTable.Parameters = map[string]string{"EXTERNAL": "TRUE", "classification": "parquet", "numFiles": "10"}
+	hiveTable.SerdeParameters = map[string]string{"path": "s3a://bucket/tab1", "serialization.format": "1"}
+	hiveTable.Description = "visits"
+	pool := &MockPool{
+		hive: &MetastoreMock{getTablesOut: []string{"tab1"}, getTableInfoOut: map[string]model.TableInfo{"tab1": hiveTable}},
+		glue: &MetastoreMock{getTablesOut: []string{"tab1"}, getTableInfoOut: map[string]model.TableInfo{"tab1": glueTable}},
+	}
+	h := &HiveGlueManager{
+		pool: pool,
+	}
+	require.NoError(t, h.Sync(metastore.GLUE, metastore.HIVE, "pls", nil, false, false))
+
+	require.Len(t, pool.hive.alterTableInfoCalls, 0)
+}
+
+// PropertiesMetastoreMock keeps only some table properties, as hive or the iceberg rest catalog.
+type PropertiesMetastoreMock struct {
+	*MetastoreMock
+	properties metastore.Properties
+}
+
+func (m *PropertiesMetastoreMock) KeptProperties() metastore.Properties {
+	return m.properties
+}
+
+func TestTableChanged_PropertiesNotKept(t *testing.T) {
+	hiveTable := getTableInfo("tab1")
+	glueTable := getTableInfo("tab1")
+	glueTable.Description = "visits"
+	glueTable.Parameters = map[string]string{"classification": "parquet"}
+	glueTable.Columns[0].Parameters = map[string]string{"iceberg.field.id": "1"}
+	hive := &PropertiesMetastoreMock{MetastoreMock: &MetastoreMock{}, properties: metastore.Properties{Description: true, TableParameters: true, SerdeParameters: true}}
+	rest := &PropertiesMetastoreMock{MetastoreMock: &MetastoreMock{}}
+	glue := &MetastoreMock{}
+	require.False(t, tableChanged(hiveTable, glueTable, comparedProperties(hive, glue)))
+	require.False(t, tableChanged(glueTable, hiveTable, comparedProperties(glue, rest)))
+	require.True(t, tableChanged(glueTable, hiveTable, comparedProperties(glue, hive)))
+	hiveTable.Description = "visits"
+	hiveTable.Parameters = map[string]string{"classification": "parquet"}
+	require.False(t, tableChanged(glueTable, hiveTable, comparedProperties(glue, hive)))
+	require.True(t, tableChanged(glueTable, hiveTable, comparedProperties(glue, glue)))
+}
+
+// FormatMetastoreMock holds only iceberg tables, as the iceberg rest catalog.
+type FormatMetastoreMock struct {
+	*MetastoreMock
+}
+
+func (m *FormatMetastoreMock) HoldsFormat(format model.TableFormat) bool {
+	return format == model.ICEBERG
+}
+
+func TestHiveGlueManager_SyncSkipsFormatsTargetCantHold(t *testing.T) {
+	source := &MetastoreMock{getTableInfoOut: map[string]model.TableInfo{
+		"tab1":    getTableInfo("tab1"),
+		"iceberg": icebergTableInfo("iceberg", "00001"),
+	}}
+	target := &FormatMetastoreMock{MetastoreMock: &MetastoreMock{}}
+	h := &HiveGlueManager{}
+	require.NoError(t, h.syncTables(source, target, "pls", []string{"tab1", "iceberg"}, nil, false))
+
+	require.Len(t, target.createTableInfoCalls, 1)
+	require.Equal(t, "iceberg", target.createTableInfoCalls[0].Tables[0].Name)
+}
+
+func icebergTableInfo(table string, version string) model.TableInfo {
+	info := getTableInfo(table)
+	info.Format = model.ICEBERG
+	info.MetadataLocation = fmt.Sprintf("s3://bucket/%s/metadata/%s-8051da97-485b-4715-b22f-6302b46c752e.metadata.json", table, version)
+	return info
+}
+
+func TestHiveGlueManager_SyncIcebergMetadataLocation(t *testing.T) {
+	pool := &MockPool{
+		hive: &MetastoreMock{getTablesOut: []string{"tab1", "tab2", "tab3"}, getTableInfoOut: map[string]model.TableInfo{
+			"tab1": icebergTableInfo("tab1", "00002"),
+			"tab2": icebergTableInfo("tab2", "00001"),
+			"tab3": icebergTableInfo("tab3", "00003"),
+		}},
+		glue: &MetastoreMock{getTablesOut: []string{"tab1", "tab2", "tab3"}, getTableInfoOut: map[string]model.TableInfo{
+			"tab1": icebergTableInfo("tab1", "00001"),
+			"tab2": icebergTableInfo("tab2", "00002"),
+			"tab3": icebergTableInfo("tab3", "00003"),
+		}},
+	}
+	h := NewHiveGlueManager(pool, nil, false)
+	require.NoError(t, h.Sync(metastore.HIVE, metastore.GLUE, "pls", nil, false, false))
+	require.Equal(t, []model.TableInfo{icebergTableInfo("tab1", "00002"), icebergTableInfo("tab2", "00001")}, pool.glue.alterTableInfoCalls[0].Tables)
+
+	pool.glue.alterTableInfoCalls = nil
+	h = NewHiveGlueManager(pool, nil, true)
+	require.Error(t, h.Sync(metastore.HIVE, metastore.GLUE, "pls", nil, false, false))
+	require.Equal(t, []model.TableInfo{icebergTableInfo("tab1", "00002")}, pool.glue.alterTableInfoCalls[0].Tables)
+}
+
+func TestHiveGlueManager_SyncIcebergTableRoot(t *testing.T) {
+	root := icebergTableInfo("tab1", "00001")
+	root.MetadataLocation = "s3://bucket/tab1"
+	pool := &MockPool{
+		hive: &MetastoreMock{getTablesOut: []string{"tab1"}, getTableInfoOut: map[string]model.TableInfo{"tab1": icebergTableInfo("tab1", "00002")}},
+		glue: &MetastoreMock{getTablesOut: []string{"tab1"}, getTableInfoOut: map[string]model.TableInfo{"tab1": root}},
+	}
+	h := NewHiveGlueManager(pool, nil, true)
+	require.NoError(t, h.Sync(metastore.HIVE, metastore.GLUE, "pls", nil, false, false))
+	require.Len(t, pool.glue.alterTableInfoCalls, 0)
+}
+
+func TestHiveGlueManager_SyncContinueOnAlterTableError(t *testing.T) {
+	changed := getTableInfo("tab1")
+	changed.MetadataLocation = "s3://other-bucket/tab1"
+	changed2 := getTableInfo("tab2")
+	changed2.MetadataLocation = "s3://other-bucket/tab2"
+	pool := &MockPool{
+		hive: &MetastoreMock{getTablesOut: []string{"tab1", "tab2"}, getTableInfoOut: map[string]model.TableInfo{
+			"tab1": changed,
+			"tab2": changed2,
+		}},
+		glue: &MetastoreMock{getTablesOut: []string{"tab1", "tab2"}, alterTableError: map[string]error{
+			"tab1": fmt.Errorf("error"),
+		}},
+	}
+	h := &HiveGlueManager{
+		pool: pool,
+	}
+	require.Error(t, h.Sync(metastore.HIVE, metastore.GLUE, "pls", nil, false, false))
+
+	require.Len(t, pool.glue.alterTableInfoCalls, 1)
+	require.Len(t, pool.glue.alterTableInfoCalls[0].Tables, 2)
+}
+
+func TestHiveGlueManager_SyncCopyMissingPartitions(t *testing.T) {
+	partitioned := getTableInfo("tab1")
+	partitioned.Partitions = []model.Column{
+		{
+			Name: "dt",
+			Type: model.ColumnType{SqlType: model.DATE},
+		},
+	}
+	partitioned2 := getTableInfo("tab2")
+	partitioned2.Partitions = partitioned.Partitions
+	pool := &MockPool{
+		hive: &MetastoreMock{
+			getTablesOut:    []string{"tab1", "tab2", "tab3"},
+			getTableInfoOut: map[string]model.TableInfo{"tab1": partitioned, "tab2": partitioned2},
+			getPartitionsOut: map[string][]model.Partition{
+				"tab1": {
+					{Values: []string{"2023-01-01"}, Location: "s3a://bucket/tab1/dt=2023-01-01"},
+					{Values: []string{"2023-01-02"}, Location: "s3a://bucket/tab1/dt=2023-01-02"},
+				},
+				"tab2": {
+					{Values: []string{"2023-01-01"}, Location: "s3a://bucket/tab2/dt=2023-01-01"},
+				},
+				"tab3": {
+					{Values: []string{"2023-01-01"}, Location: "s3a://bucket/tab3/dt=2023-01-01"},
+				},
+			},
+		},
+		glue: &MetastoreMock{
+			getTablesOut:    []string{"tab1"},
+			getTableInfoOut: map[string]model.TableInfo{"tab1": partitioned},
+			getPartitionsOut: map[string][]model.Partition{
+				"tab1": {
+					{Values: []string{"2023-01-01"}, Location: "s3://bucket/tab1/dt=2023-01-01"},
+				},
+			},
+		},
+	}
+	h := &HiveGlueManager{
+		pool: pool,
+	}
+	require.NoError(t, h.Sync(metastore.HIVE, metastore.GLUE, "pls", nil, false, false))
+
+	require.Len(t, pool.glue.alterTableInfoCalls, 0)
+	require.Len(t, pool.glue.createTableInfoCalls, 1)
+	require.Len(t, pool.glue.createTableInfoCalls[0].Tables, 2)
+	require.Equal(t, map[string][]model.Partition{
+		"tab1": {{Values: []string{"2023-01-02"}, Location: "s3a://bucket/tab1/dt=2023-01-02"}},
+		"tab2": {{Values: []string{"2023-01-01"}, Location: "s3a://bucket/tab2/dt=2023-01-01"}},
+	}, pool.glue.addPartitionsCalls)
+}
+
+func TestHiveGlueManager_SyncErrorOnAddPartitionsError(t *testing.T) {
+	partitioned := getTableInfo("tab1")
+	partitioned.Partitions = []model.Column{
+		{
+			Name: "dt",
+			Type: model.ColumnType{SqlType: model.DATE},
+		},
+	}
+	pool := &MockPool{
+		hive: &MetastoreMock{
+			getTablesOut:    []string{"tab1"},
+			getTableInfoOut: map[string]model.TableInfo{"tab1": partitioned},
+			getPartitionsOut: map[string][]model.Partition{
+				"tab1": {{Values: []string{"2023-01-01"}, Location: "s3a://bucket/tab1/dt=2023-01-01"}},
+			},
+		},
+		glue: &MetastoreMock{getTablesOut: []string{}, addPartitionsError: fmt.Errorf("error")},
+	}
+	h := &HiveGlueManager{
+		pool: pool,
+	}
+	require.Error(t, h.Sync(metastore.HIVE, metastore.GLUE, "pls", nil, false, false))
+	require.Len(t, pool.glue.addPartitionsCalls["tab1"], 1)
+}
+
+func TestHiveGlueManager_SyncCreateMissingDatabase(t *testing.T) {
+	pool := &MockPool{hive: &MetastoreMock{getTablesOut: []string{"tab1"}}, glue: &MetastoreMock{getDatabasesOut: []string{"other"}}}
+	h := &HiveGlueManager{
+		pool: pool,
+	}
+	require.NoError(t, h.Sync(metastore.HIVE, metastore.GLUE, "pls", nil, false, true))
+
+	require.Equal(t, []model.Database{getDatabase("pls")}, pool.glue.createDatabaseCalls)
+	require.Len(t, pool.glue.createTableInfoCalls, 1)
+}
+
+func TestHiveGlueManager_SyncSkipExistingDatabase(t *testing.T) {
+	pool := &MockPool{hive: &MetastoreMock{getTablesOut: []string{"tab1"}}, glue: &MetastoreMock{getDatabasesOut: []string{"pls"}}}
+	h := &HiveGlueManager{
+		pool: pool,
+	}
+	require.NoError(t, h.Sync(metastore.HIVE, metastore.GLUE, "pls", nil, false, true))
+
+	require.Len(t, pool.glue.createDatabaseCalls, 0)
+	require.Len(t, pool.glue.createTableInfoCalls, 1)
+}
+
+func TestHiveGlueManager_SyncErrorWhenSourceDatabaseError(t *testing.T) {
+	pool := &MockPool{hive: &MetastoreMock{databaseError: map[string]error{"pls": fmt.Errorf("error")}}, glue: &MetastoreMock{}}
+	h := &HiveGlueManager{
+		pool: pool,
+	}
+	require.Error(t, h.Sync(metastore.HIVE, metastore.GLUE, "pls", nil, false, true))
+
+	require.Len(t, pool.glue.createDatabaseCalls, 0)
+	require.Len(t, pool.glue.createTableInfoCalls, 0)
+}
+
+func TestHiveGlueManager_GetDatabases(t *testing.T) {
+	pool := &MockPool{hive: &MetastoreMock{getDatabasesOut: []string{"pls", "other"}}, glue: &MetastoreMock{}}
+	h := NewHiveGlueManager(pool, nil, false)
+	got, err := h.GetDatabases(metastore.HIVE)
+	require.NoError(t, err)
+	require.Equal(t, []string{"pls", "other"}, got)
+
+	_, err = h.GetDatabases("no")
+	require.Error(t, err)
+	require.Equal(t, []metastore.MetastoreCode{metastore.GLUE, metastore.HIVE}, h.Metastores())
+}
+
+type IcebergReaderMock struct {
+	metadata map[string]iceberg.TableMetadata
+}
+
+func (i *IcebergReaderMock) ReadMetadata(location string) (iceberg.TableMetadata, error) {
+	metadata, found := i.metadata[location]
+	if !found {
+		return iceberg.TableMetadata{}, fmt.Errorf("not found")
+	}
+	return metadata, nil
+}
+
+func (i *IcebergReaderMock) LatestMetadataLocation(tableLocation string) (string, error) {
+	for location := range i.metadata {
+		if strings.HasPrefix(location, tableLocation+"/metadata/") {
+			return location, nil
+		}
+	}
+	return "", fmt.Errorf("not found")
+}
+
+func TestHiveGlueManager_CreateIcebergFromMetadata(t *testing.T) {
+	withSchema := icebergTableInfo("tab1", "00001")
+	withoutSchema := icebergTableInfo("tab2", "00001")
+	withoutSchema.Columns = nil
+	missing := icebergTableInfo("tab3", "00001")
+	missing.Columns = nil
+	reader := &IcebergReaderMock{metadata: map[string]iceberg.TableMetadata{
+		withoutSchema.MetadataLocation: {
+			Schemas: []iceberg.Schema{{Fields: []iceberg.Field{
+				{ID: 1, Name: "id", Type: []byte(`"long"`)},
+				{ID: 2, Name: "name", Type: []byte(`"string"`)},
+			}}},
+		},
+	}}
+	pool := NewMockPool()
+	h := NewHiveGlueManager(pool, reader, false)
+	require.Error(t, h.Create([]metastore.MetastoreCode{metastore.GLUE}, []model.DatabaseTables{
+		{Db: "pls", Tables: []model.TableInfo{withSchema, withoutSchema, missing}},
+	}))
+
+	filled := withoutSchema
+	filled.Columns = []model.Column{
+		{Name: "id", Type: model.ColumnType{SqlType: model.BIGINT}},
+		{Name: "name", Type: model.ColumnType{SqlType: model.STRING}},
+	}
+	require.Equal(t, []model.TableInfo{withSchema, filled}, pool.glue.createTableInfoCalls[0].Tables)
+}
+
+func TestHiveGlueManager_RegisterIceberg(t *testing.T) {
+	table := icebergTableInfo("tab1", "00003")
+	table.Columns = nil
+	reader := &IcebergReaderMock{metadata: map[string]iceberg.TableMetadata{
+		table.MetadataLocation: {
+			Schemas: []iceberg.Schema{{Fields: []iceberg.Field{{ID: 1, Name: "id", Type: []byte(`"long"`)}}}},
+		},
+	}}
+	pool := NewMockPool()
+	h := NewHiveGlueManager(pool, reader, false)
+	require.NoError(t, h.RegisterIceberg([]metastore.MetastoreCode{metastore.GLUE}, "pls", "tab1", "s3://bucket/tab1"))
+	table.Columns = []model.Column{{Name: "id", Type: model.ColumnType{SqlType: model.BIGINT}}}
+	require.Equal(t, []model.TableInfo{table}, pool.glue.createTableInfoCalls[0].Tables)
+
+	require.Error(t, h.RegisterIceberg([]metastore.MetastoreCode{metastore.GLUE}, "pls", "tab2", "s3://bucket/tab2"))
+	require.Error(t, NewHiveGlueManager(pool, nil, false).RegisterIceberg([]metastore.MetastoreCode{metastore.GLUE}, "pls", "tab1", "s3://bucket/tab1"))
+}
+
+func TestHiveGlueManager_CreateDatabases(t *testing.T) {
+	pool := &MockPool{hive: &MetastoreMock{}, glue: &MetastoreMock{databaseError: map[string]error{"db1": fmt.Errorf("error")}}}
+	h := NewHiveGlueManager(pool, nil, false)
+	databases := []model.Database{getDatabase("db1"), getDatabase("db2")}
+	require.Error(t, h.CreateDatabases([]metastore.MetastoreCode{"no", metastore.HIVE, metastore.GLUE}, databases))
+
+	require.Equal(t, databases, pool.hive.createDatabaseCalls)
+	require.Equal(t, databases, pool.glue.createDatabaseCalls)
+}
+
+func TestHiveGlueManager_DropDatabases(t *testing.T) {
+	pool := &MockPool{hive: &MetastoreMock{databaseError: map[string]error{"db1": fmt.Errorf("error")}}, glue: &MetastoreMock{}}
+	h := NewHiveGlueManager(pool, nil, false)
+	require.NoError(t, h.DropDatabases(metastore.GLUE, []string{"db1", "db2"}, true))
+	require.Equal(t, []string{"db1", "db2"}, pool.glue.dropDatabaseCalls)
+
+	require.Error(t, h.DropDatabases(metastore.HIVE, []string{"db1", "db2"}, false))
+	require.Equal(t, []string{"db1", "db2"}, pool.hive.dropDatabaseCalls)
+
+	require.Error(t, h.DropDatabases("no", []string{"db1"}, false))
+}
+
+func TestHiveGlueManager_SyncDatabases(t *testing.T) {
+	pool := &MockPool{
+		hive: &MetastoreMock{getDatabasesOut: []string{"pls", "pls_missing", "other"}, getTablesOut: []string{"tab1"}},
+		glue: &MetastoreMock{getTablesOut: []string{}},
+	}
+	h := NewHiveGlueManager(pool, nil, false)
+	results, err := h.SyncDatabases(metastore.HIVE, metastore.GLUE, "pls*", false, false)
+	require.Error(t, err)
+	require.Len(t, results, 2)
+	require.Equal(t, model.SyncResult{Db: "pls"}, results[0])
+	require.Equal(t, "pls_missing", results[1].Db)
+	require.NotEmpty(t, results[1].Error)
+
+	require.Len(t, pool.glue.createTableInfoCalls, 1)
+	require.Equal(t, "pls", pool.glue.createTableInfoCalls[0].Db)
+}
+
+func TestHiveGlueManager_SyncAllDatabases(t *testing.T) {
+	pool := &MockPool{
+		hive: &MetastoreMock{getDatabasesOut: []string{"pls"}, getTablesOut: []string{"tab1"}},
+		glue: &MetastoreMock{getTablesOut: []string{}},
+	}
+	h := NewHiveGlueManager(pool, nil, false)
+	results, err := h.SyncDatabases(metastore.HIVE, metastore.GLUE, "*", false, false)
+	require.NoError(t, err)
+	require.Equal(t, []model.SyncResult{{Db: "pls"}}, results)
+	require.Len(t, pool.glue.createTableInfoCalls, 1)
+}
+
+func TestHiveGlueManager_SyncDatabasesErrors(t *testing.T) {
+	h := NewHiveGlueManager(NewMockPool(), nil, false)
+	_, err := h.SyncDatabases("no", metastore.GLUE, "*", false, false)
+	require.Error(t, err)
+	_, err = h.SyncDatabases(metastore.HIVE, metastore.GLUE, "[", false, false)
+	require.Error(t, err)
+}
+
+func getDatabase(dbName string) model.Database {
+	return model.Database{
+		Name:        dbName,
+		Description: "description",
+		Location:    fmt.Sprintf("s3://bucket/%s", dbName),
+		Parameters:  map[string]string{"owner": "team"},
+	}
+}
+
 func getTableInfo(table string) model.TableInfo {
 	return model.TableInfo{
 		Name: table,
@@ -484,3 +1089,12 @@ func getMetastore(pool *MockPool, met metastore.MetastoreCode) *MetastoreMock {
 	}
 	return mock
 }
+
+func TestLocationChanged_GzipMetadata(t *testing.T) {
+	source := icebergTableInfo("tab1", "00002")
+	source.MetadataLocation += ".gz"
+	target := icebergTableInfo("tab1", "00001")
+	require.True(t, locationChanged(source, target))
+	target.MetadataLocation = source.MetadataLocation
+	require.False(t, locationChanged(source, target))
+}
@@ -7,30 +7,62 @@ import (
 )
 
 type AuxInfoRetriever interface {
-	GetTableProperty(ctx context.Context, table, tableParam string) (string, error)
+	GetTableProperty(ctx context.Context, dbName, table, tableParam string) (string, error)
+	// GetTablesProperty returns the property of the tables of the database having it, by table name.
+	GetTablesProperty(ctx context.Context, dbName, tableParam string) (map[string]string, error)
 }
 
-type PgAuxInfoRetriever struct {
-	db *sql.DB
+const (
+	auxTablePropertyQuery = `SELECT tp."PARAM_VALUE"
+		FROM "TBLS" t
+		JOIN "DBS" d ON t."DB_ID" = d."DB_ID"
+		JOIN "TABLE_PARAMS" tp ON t."TBL_ID" = tp."TBL_ID"
+		WHERE d."NAME" = $1 AND t."TBL_NAME" = $2 AND tp."PARAM_KEY" = $3`
+
+	auxTablesPropertyQuery = `SELECT t."TBL_NAME", tp."PARAM_VALUE"
+		FROM "TBLS" t
+		JOIN "DBS" d ON t."DB_ID" = d."DB_ID"
+		JOIN "TABLE_PARAMS" tp ON t."TBL_ID" = tp."TBL_ID"
+		WHERE d."NAME" = $1 AND tp."PARAM_KEY" = $2`
+)
+
+// DbAuxInfoRetriever reads table properties from the database backing the hive metastore.
+type DbAuxInfoRetriever struct {
+	db      *sql.DB
+	dialect SqlDialect
 }
 
-func NewPgAuxInfoRetriever(db *sql.DB) *PgAuxInfoRetriever {
-	return &PgAuxInfoRetriever{db: db}
+func NewAuxInfoRetriever(dialect SqlDialect, db *sql.DB) *DbAuxInfoRetriever {
+	return &DbAuxInfoRetriever{db: db, dialect: dialect}
 }
 
-func (p *PgAuxInfoRetriever) GetTableProperty(ctx context.Context, table, tableParam string) (string, error) {
-	row := p.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT "PARAM_VALUE"
-			FROM "TBLS" t JOIN
-     		"TABLE_PARAMS" tp ON t."TBL_ID" = tp."TBL_ID"
-			WHERE t."TBL_NAME" = '%s'
-			AND "PARAM_KEY" = '%s'`, table, tableParam))
-	if row.Err() != nil {
-		return "", row.Err()
-	}
-	var paramValue string
+func (a *DbAuxInfoRetriever) GetTableProperty(ctx context.Context, dbName, table, tableParam string) (string, error) {
+	row := a.db.QueryRowContext(ctx, a.dialect.rebind(auxTablePropertyQuery), dbName, table, tableParam)
+	var paramValue sql.NullString
 	err := row.Scan(&paramValue)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("table %s.%s has no %s property", dbName, table, tableParam)
+	}
 	if err != nil {
 		return "", err
 	}
-	return paramValue, nil
+	return paramValue.String, nil
+}
+
+func (a *DbAuxInfoRetriever) GetTablesProperty(ctx context.Context, dbName, tableParam string) (map[string]string, error) {
+	rows, err := a.db.QueryContext(ctx, a.dialect.rebind(auxTablesPropertyQuery), dbName, tableParam)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	properties := make(map[string]string)
+	for rows.Next() {
+		var table string
+		var paramValue sql.NullString
+		if err := rows.Scan(&table, &paramValue); err != nil {
+			return nil, err
+		}
+		properties[table] = paramValue.String
+	}
+	return properties, rows.Err()
 }
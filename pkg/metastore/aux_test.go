@@ -0,0 +1,83 @@
+package metastore
+
+import (
+	"context"
+	"database/sql/driver"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDbAuxInfoRetriever_GetTableProperty(t *testing.T) {
+	tests := []struct {
+		name      string
+		dialect   SqlDialect
+		wantQuery string
+	}{
+		{
+			name:      "shouldPostgres",
+			dialect:   POSTGRES,
+			wantQuery: `WHERE d."NAME" = $1 AND t."TBL_NAME" = $2 AND tp."PARAM_KEY" = $3`,
+		},
+		{
+			name:      "shouldMysql",
+			dialect:   MYSQL,
+			wantQuery: "WHERE d.`NAME` = ? AND t.`TBL_NAME` = ? AND tp.`PARAM_KEY` = ?",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := NewFakeSqlDb(t, SqlResult{match: "TABLE_PARAMS", columns: []string{"PARAM_VALUE"}, rows: [][]driver.Value{
+				{"s3://bucket/pls/events/metadata/00001-aaaa.metadata.json"},
+			}})
+			got, err := NewAuxInfoRetriever(tt.dialect, db).GetTableProperty(context.Background(), "pls", "events' OR '1'='1", "metadata_location")
+			require.NoError(t, err)
+			require.Equal(t, "s3://bucket/pls/events/metadata/00001-aaaa.metadata.json", got)
+			require.Len(t, fake.queries, 1)
+			require.Contains(t, fake.queries[0].query, tt.wantQuery)
+			require.Equal(t, []driver.Value{"pls", "events' OR '1'='1", "metadata_location"}, fake.queries[0].args)
+		})
+	}
+}
+
+func TestDbAuxInfoRetriever_GetTablePropertyMissing(t *testing.T) {
+	db, _ := NewFakeSqlDb(t, SqlResult{match: "TABLE_PARAMS", columns: []string{"PARAM_VALUE"}})
+	_, err := NewAuxInfoRetriever(POSTGRES, db).GetTableProperty(context.Background(), "pls", "events", "metadata_location")
+	require.EqualError(t, err, "table pls.events has no metadata_location property")
+}
+
+func TestDbAuxInfoRetriever_GetTablesProperty(t *testing.T) {
+	for _, dialect := range []SqlDialect{POSTGRES, MYSQL} {
+		t.Run(string(dialect), func(t *testing.T) {
+			db, fake := NewFakeSqlDb(t, SqlResult{match: "TABLE_PARAMS", columns: []string{"TBL_NAME", "PARAM_VALUE"}, rows: [][]driver.Value{
+				{"events", "s3://bucket/pls/events/metadata/00001-aaaa.metadata.json"},
+				{"clicks", "s3://bucket/pls/clicks/metadata/00004-bbbb.metadata.json"},
+			}})
+			got, err := NewAuxInfoRetriever(dialect, db).GetTablesProperty(context.Background(), "pls", "metadata_location")
+			require.NoError(t, err)
+			require.Equal(t, map[string]string{
+				"events": "s3://bucket/pls/events/metadata/00001-aaaa.metadata.json",
+				"clicks": "s3://bucket/pls/clicks/metadata/00004-bbbb.metadata.json",
+			}, got)
+			require.Len(t, fake.queries, 1)
+			require.Contains(t, fake.queries[0].query, dialect.rebind(`d."NAME" = $1 AND tp."PARAM_KEY" = $2`))
+			require.Equal(t, []driver.Value{"pls", "metadata_location"}, fake.queries[0].args)
+		})
+	}
+}
+
+func TestDialectFromDriver(t *testing.T) {
+	dialect, err := DialectFromDriver("postgres")
+	require.NoError(t, err)
+	require.Equal(t, POSTGRES, dialect)
+	dialect, err = DialectFromDriver("mysql")
+	require.NoError(t, err)
+	require.Equal(t, MYSQL, dialect)
+	_, err = DialectFromDriver("sqlite3")
+	require.Error(t, err)
+}
+
+func TestSqlDialect_rebind(t *testing.T) {
+	query := `SELECT t."TBL_NAME" FROM "TBLS" t WHERE t."DB_ID" = $1 AND t."TBL_NAME" = $2`
+	require.Equal(t, query, POSTGRES.rebind(query))
+	require.Equal(t, "SELECT t.`TBL_NAME` FROM `TBLS` t WHERE t.`DB_ID` = ? AND t.`TBL_NAME` = ?", MYSQL.rebind(query))
+}
@@ -1,6 +1,8 @@
 package metastore
 
 import (
+	"fmt"
+	"github.com/the-Data-Appeal-Company/metaman/pkg/iceberg"
 	"github.com/the-Data-Appeal-Company/metaman/pkg/model"
 	"strings"
 )
@@ -25,21 +27,124 @@ func isOnS3(location string) bool {
 func getMetadataLocation(metastoreCode MetastoreCode, table model.TableInfo) string {
 	location := convertS3Format(metastoreCode, table.MetadataLocation)
 	switch table.Format {
-	case model.PARQUET:
+	case model.PARQUET, model.DELTA, model.HUDI_COW, model.HUDI_MOR, model.ORC, model.AVRO, model.CSV, model.TEXTFILE, model.JSON, model.UNKNOWN:
 		return location
-	case model.ICEBERG:
-		if strings.Contains(location, "/metadata/") {
-			return location[0:strings.LastIndex(location, "/metadata/")]
+	case model.DELTA_SYMLINK:
+		location = strings.TrimSuffix(location, "/")
+		if strings.HasSuffix(location, "/"+model.DeltaSymlinkManifestPath) {
+			return location
 		}
-		return location
+		return location + "/" + model.DeltaSymlinkManifestPath
+	case model.ICEBERG:
+		return iceberg.TableRoot(location)
 	default:
 		return ""
 	}
 }
 
+// dataLocation is the location holding the table files, deleted along with the table.
+func dataLocation(table model.TableInfo) string {
+	if table.Format == model.ICEBERG {
+		return iceberg.TableRoot(table.MetadataLocation)
+	}
+	return table.MetadataLocation
+}
+
+// previousMetadataLocation returns the metadata file an iceberg table pointed to
+// before being altered to updated, empty if it does not change.
+func previousMetadataLocation(current, updated string) string {
+	if current == updated {
+		return ""
+	}
+	return current
+}
+
+// tableLocation returns the table root for a location read from a metastore,
+// delta symlink tables being registered on their manifest directory.
+func tableLocation(format model.TableFormat, location string) string {
+	if format == model.DELTA_SYMLINK {
+		location = strings.TrimSuffix(location, "/")
+		return strings.TrimSuffix(location, "/"+model.DeltaSymlinkManifestPath)
+	}
+	return location
+}
+
+// serDeInfo returns the serde of the table format with the table serde parameters
+// (delimiters, quote char, avro schema...), the format defaults only filling tables
+// without any, e.g. defined in a file, so that synced tables keep their source ones.
+// Spark delta and hudi tables also need the table path among them.
+func serDeInfo(metastoreCode MetastoreCode, table model.TableInfo) *model.SerDeInfo {
+	if table.Format == model.UNKNOWN {
+		if table.Storage == nil || (table.Storage.SerializationLib == "" && len(table.SerdeParameters) == 0) {
+			return nil
+		}
+		return &model.SerDeInfo{
+			SerializationLib: table.Storage.SerializationLib,
+			Parameters:       mergeParameters(nil, table.SerdeParameters),
+		}
+	}
+	info := table.Format.SerDeInfo()
+	if info == nil {
+		return nil
+	}
+	if len(table.SerdeParameters) > 0 {
+		info.Parameters = mergeParameters(nil, table.SerdeParameters)
+	}
+	switch table.Format {
+	case model.DELTA, model.HUDI_COW, model.HUDI_MOR:
+		info.Parameters["path"] = getMetadataLocation(metastoreCode, table)
+	}
+	if table.IsHudiReadOptimized() {
+		info.Parameters[model.HudiReadOptimizedSerde] = "true"
+	}
+	return info
+}
+
+// tableParameters merges the table parameters with the ones required by its format,
+// the latter taking precedence.
+func tableParameters(metastoreCode MetastoreCode, table model.TableInfo) map[string]string {
+	return mergeParameters(table.Parameters, table.Format.Parameters(convertS3Format(metastoreCode, table.MetadataLocation)))
+}
+
+func filterParameters(parameters map[string]string, excluded []string) map[string]string {
+	filtered := make(map[string]string, len(parameters))
+	for k, v := range parameters {
+		filtered[k] = v
+	}
+	for _, k := range excluded {
+		delete(filtered, k)
+	}
+	return filtered
+}
+
+func validateColumnTypes(table model.TableInfo) error {
+	for _, columns := range [][]model.Column{table.Columns, table.Partitions} {
+		for _, column := range columns {
+			if err := column.Type.Validate(); err != nil {
+				return fmt.Errorf("table %s, column %s: %s", table.Name, column.Name, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// sortAscending and sortDescending are the sort orders of bucketed tables,
+// encoded the same way by hive and glue.
+const (
+	sortAscending  = 1
+	sortDescending = 0
+)
+
+func sortOrder(ascending bool) int32 {
+	if ascending {
+		return sortAscending
+	}
+	return sortDescending
+}
+
 func convertS3Format(metastoreCode MetastoreCode, location string) string {
 	switch metastoreCode {
-	case GLUE:
+	case GLUE, ICEBERG_REST:
 		location = strings.ReplaceAll(location, "s3a://", "s3://")
 	case HIVE:
 		location = strings.ReplaceAll(location, "s3://", "s3a://")
@@ -47,9 +152,34 @@ func convertS3Format(metastoreCode MetastoreCode, location string) string {
 	return location
 }
 
+func ptrFromString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
 func stringFromPtr(s *string) string {
 	if s == nil {
 		return ""
 	}
 	return *s
 }
+
+func mergeParameters(current, updated map[string]string) map[string]string {
+	merged := make(map[string]string, len(current)+len(updated))
+	for k, v := range current {
+		merged[k] = v
+	}
+	for k, v := range updated {
+		merged[k] = v
+	}
+	return merged
+}
+
+func batchEnd(start, batchSize, length int) int {
+	if start+batchSize > length {
+		return length
+	}
+	return start + batchSize
+}
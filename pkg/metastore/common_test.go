@@ -102,6 +102,39 @@ func Test_getMetadataLocation(t *testing.T) {
 			},
 			want: "s3a://bucket/tests/schema/table",
 		},
+		{
+			name: "shouldGetMetadataLocationDeltaGlue",
+			args: args{
+				metastoreCode: GLUE,
+				table: model.TableInfo{
+					MetadataLocation: "s3a://bucket/tests/schema/table",
+					Format:           model.DELTA,
+				},
+			},
+			want: "s3://bucket/tests/schema/table",
+		},
+		{
+			name: "shouldGetMetadataLocationDeltaSymlinkGlue",
+			args: args{
+				metastoreCode: GLUE,
+				table: model.TableInfo{
+					MetadataLocation: "s3://bucket/tests/schema/table/",
+					Format:           model.DELTA_SYMLINK,
+				},
+			},
+			want: "s3://bucket/tests/schema/table/_symlink_format_manifest",
+		},
+		{
+			name: "shouldKeepManifestLocationDeltaSymlinkHive",
+			args: args{
+				metastoreCode: HIVE,
+				table: model.TableInfo{
+					MetadataLocation: "s3://bucket/tests/schema/table/_symlink_format_manifest",
+					Format:           model.DELTA_SYMLINK,
+				},
+			},
+			want: "s3a://bucket/tests/schema/table/_symlink_format_manifest",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -109,3 +142,13 @@ func Test_getMetadataLocation(t *testing.T) {
 		})
 	}
 }
+
+func Test_serDeInfoDelta(t *testing.T) {
+	info := serDeInfo(HIVE, model.TableInfo{
+		MetadataLocation: "s3://bucket/tests/schema/table",
+		Format:           model.DELTA,
+	})
+	require.Equal(t, "org.apache.hadoop.hive.serde2.lazy.LazySimpleSerDe", info.SerializationLib)
+	require.Equal(t, map[string]string{"serialization.format": "1", "path": "s3a://bucket/tests/schema/table"}, info.Parameters)
+	require.Equal(t, "s3://bucket/tests/schema/table", tableLocation(model.DELTA_SYMLINK, "s3://bucket/tests/schema/table/_symlink_format_manifest/"))
+}
@@ -0,0 +1,47 @@
+package metastore
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// SqlDialect is the dialect of the database backing the hive metastore.
+type SqlDialect string
+
+const (
+	POSTGRES SqlDialect = "postgres"
+	MYSQL    SqlDialect = "mysql"
+)
+
+// DialectFromDriver returns the dialect spoken through the given database/sql driver.
+func DialectFromDriver(driver string) (SqlDialect, error) {
+	switch driver {
+	case "postgres", "pgx":
+		return POSTGRES, nil
+	case "mysql":
+		return MYSQL, nil
+	default:
+		return "", fmt.Errorf("db driver %s not supported", driver)
+	}
+}
+
+var postgresPlaceholder = regexp.MustCompile(`\$\d+`)
+
+// rebind adapts a query written for postgres to the dialect: mysql quotes identifiers
+// with backticks and has positional ? placeholders, so arguments must follow their order.
+func (d SqlDialect) rebind(query string) string {
+	if d != MYSQL {
+		return query
+	}
+	query = strings.ReplaceAll(query, `"`, "`")
+	return postgresPlaceholder.ReplaceAllString(query, "?")
+}
+
+// currentSchema is the expression of the schema, or mysql database, queries run in.
+func (d SqlDialect) currentSchema() string {
+	if d == MYSQL {
+		return "DATABASE()"
+	}
+	return "current_schema()"
+}
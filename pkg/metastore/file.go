@@ -0,0 +1,260 @@
+package metastore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/the-Data-Appeal-Company/metaman/pkg/model"
+	"gopkg.in/yaml.v2"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// file metastore formats
+const (
+	FileFormatYaml = "yaml"
+	FileFormatJson = "json"
+)
+
+// FileMetaStore is a metastore backed by a directory tree, e.g. a git repository: each database is a
+// directory of root holding a <table>.yaml file per table with its serialized model.TableInfo, and
+// the database info is in the optional <db>.yaml file next to it. Partitions are not kept.
+type FileMetaStore struct {
+	root               string
+	format             string
+	excludedParameters []string
+}
+
+// NewFileMetaStore creates the metastore of the root directory, writing the files as yaml or json.
+func NewFileMetaStore(root, format string, excludedParameters []string) (*FileMetaStore, error) {
+	if format == "" {
+		format = FileFormatYaml
+	}
+	if format != FileFormatYaml && format != FileFormatJson {
+		return nil, fmt.Errorf("%s metastore format %s not supported", FILE, format)
+	}
+	if root == "" {
+		return nil, fmt.Errorf("%s metastore has no path", FILE)
+	}
+	return &FileMetaStore{root: root, format: format, excludedParameters: excludedParameters}, nil
+}
+
+func (f *FileMetaStore) GetDatabases() ([]string, error) {
+	entries, err := os.ReadDir(f.root)
+	if err != nil {
+		return nil, err
+	}
+	dbs := make([]string, 0)
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			dbs = append(dbs, entry.Name())
+		}
+	}
+	return dbs, nil
+}
+
+// GetDatabaseInfo reads the database file, a database directory without it only has its name.
+func (f *FileMetaStore) GetDatabaseInfo(dbName string) (model.Database, error) {
+	dir, err := f.databaseDir(dbName)
+	if err != nil {
+		return model.Database{}, err
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return model.Database{}, fmt.Errorf("database %s not found: %w", dbName, err)
+	}
+	database := model.Database{Name: dbName}
+	err = f.read(dir+f.extension(), &database)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return model.Database{}, err
+	}
+	database.Name = dbName
+	return database, nil
+}
+
+func (f *FileMetaStore) CreateDatabase(database model.Database) error {
+	dir, err := f.databaseDir(database.Name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return f.write(dir+f.extension(), database)
+}
+
+func (f *FileMetaStore) DropDatabase(dbName string, cascade bool) error {
+	tables, err := f.GetTables(dbName)
+	if err != nil {
+		return err
+	}
+	if len(tables) > 0 && !cascade {
+		return fmt.Errorf("database %s is not empty", dbName)
+	}
+	dir, err := f.databaseDir(dbName)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	return removeIfExists(dir + f.extension())
+}
+
+func (f *FileMetaStore) GetTables(dbName string) ([]string, error) {
+	dir, err := f.databaseDir(dbName)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	tables := make([]string, 0)
+	for _, entry := range entries {
+		name, found := strings.CutSuffix(entry.Name(), f.extension())
+		if found && !entry.IsDir() && !strings.HasPrefix(name, ".") {
+			tables = append(tables, name)
+		}
+	}
+	return tables, nil
+}
+
+// GetTableInfo reads the table file, the table taking the name of the file.
+func (f *FileMetaStore) GetTableInfo(dbName, tableName string) (model.TableInfo, error) {
+	file, err := f.tableFile(dbName, tableName)
+	if err != nil {
+		return model.TableInfo{}, err
+	}
+	var info model.TableInfo
+	if err := f.read(file, &info); err != nil {
+		return model.TableInfo{}, fmt.Errorf("table %s.%s: %w", dbName, tableName, err)
+	}
+	info.Name = tableName
+	info.Parameters = filterParameters(info.Parameters, f.excludedParameters)
+	return info, nil
+}
+
+func (f *FileMetaStore) CreateTable(dbName string, table model.TableInfo) error {
+	file, err := f.tableFile(dbName, table.Name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(file); err == nil {
+		return fmt.Errorf("table %s.%s already exists", dbName, table.Name)
+	}
+	return f.writeTable(file, table)
+}
+
+func (f *FileMetaStore) AlterTable(dbName string, table model.TableInfo) error {
+	file, err := f.tableFile(dbName, table.Name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(file); err != nil {
+		return fmt.Errorf("table %s.%s not found: %w", dbName, table.Name, err)
+	}
+	return f.writeTable(file, table)
+}
+
+// DropTable removes the table file if any, the metastore holds no data to delete.
+func (f *FileMetaStore) DropTable(dbName string, tableName string, deleteData bool) error {
+	file, err := f.tableFile(dbName, tableName)
+	if err != nil {
+		return err
+	}
+	if deleteData {
+		logrus.Warnf("table %s.%s: %s metastore has no data to delete", dbName, tableName, FILE)
+	}
+	return removeIfExists(file)
+}
+
+// GetPartitions returns no partitions, only the table definitions are kept in files.
+func (f *FileMetaStore) GetPartitions(dbName, tableName string) ([]model.Partition, error) {
+	return []model.Partition{}, nil
+}
+
+// AddPartitions ignores the partitions, so that live metastores can be synced to files.
+func (f *FileMetaStore) AddPartitions(dbName, tableName string, partitions []model.Partition) error {
+	if len(partitions) > 0 {
+		logrus.Debugf("table %s.%s: %s metastore doesn't keep partitions, skipping %d", dbName, tableName, FILE, len(partitions))
+	}
+	return nil
+}
+
+func (f *FileMetaStore) DropPartitions(dbName, tableName string, partitions []model.Partition) error {
+	return nil
+}
+
+func (f *FileMetaStore) writeTable(file string, table model.TableInfo) error {
+	if err := validateColumnTypes(table); err != nil {
+		return err
+	}
+	table.Parameters = filterParameters(table.Parameters, f.excludedParameters)
+	return f.write(file, table)
+}
+
+func (f *FileMetaStore) extension() string {
+	return "." + f.format
+}
+
+func (f *FileMetaStore) databaseDir(dbName string) (string, error) {
+	if err := validFileName(dbName); err != nil {
+		return "", fmt.Errorf("database %s: %w", dbName, err)
+	}
+	return filepath.Join(f.root, dbName), nil
+}
+
+func (f *FileMetaStore) tableFile(dbName, tableName string) (string, error) {
+	dir, err := f.databaseDir(dbName)
+	if err != nil {
+		return "", err
+	}
+	if err := validFileName(tableName); err != nil {
+		return "", fmt.Errorf("table %s: %w", tableName, err)
+	}
+	return filepath.Join(dir, tableName+f.extension()), nil
+}
+
+func (f *FileMetaStore) read(file string, out interface{}) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if f.format == FileFormatJson {
+		return json.Unmarshal(data, out)
+	}
+	return yaml.Unmarshal(data, out)
+}
+
+func (f *FileMetaStore) write(file string, in interface{}) error {
+	var data []byte
+	var err error
+	if f.format == FileFormatJson {
+		data, err = json.MarshalIndent(in, "", "  ")
+		data = append(data, '\n')
+	} else {
+		data, err = yaml.Marshal(in)
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0644)
+}
+
+// validFileName rejects names that would point outside their directory.
+func validFileName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid name for the %s metastore", FILE)
+	}
+	return nil
+}
+
+func removeIfExists(file string) error {
+	if err := os.Remove(file); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
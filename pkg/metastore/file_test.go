@@ -0,0 +1,224 @@
+package metastore
+
+import (
+	"github.com/stretchr/testify/require"
+	"github.com/the-Data-Appeal-Company/metaman/pkg/model"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func fileTestTable() model.TableInfo {
+	return model.TableInfo{
+		Name: "events",
+		Columns: []model.Column{
+			{Name: "id", Type: model.ColumnType{SqlType: model.BIGINT}, Comment: "event id"},
+			{Name: "tags", Type: model.ColumnType{SqlType: model.MAP, Key: &model.ColumnType{SqlType: model.STRING}, Value: &model.ColumnType{SqlType: model.STRING}}},
+			{Name: "amount", Type: model.ColumnType{SqlType: model.DECIMAL, Precision: 10, Scale: 2}},
+		},
+		Partitions:       []model.Column{{Name: "dt", Type: model.ColumnType{SqlType: model.STRING}}},
+		MetadataLocation: "s3://bucket/pls/events",
+		Format:           model.PARQUET,
+		Parameters:       map[string]string{"classification": "parquet", "version": "2024"},
+		Description:      "events: raw",
+		Owner:            "data",
+		Bucketing:        &model.Bucketing{Columns: []string{"id"}, NumBuckets: 8},
+	}
+}
+
+func TestFileMetaStore_TableRoundTrip(t *testing.T) {
+	for _, format := range []string{FileFormatYaml, FileFormatJson} {
+		t.Run(format, func(t *testing.T) {
+			root := t.TempDir()
+			f, err := NewFileMetaStore(root, format, []string{"transient_lastDdlTime"})
+			require.NoError(t, err)
+			require.NoError(t, f.CreateDatabase(model.Database{Name: "pls", Description: "places", Location: "s3://bucket/pls"}))
+			table := fileTestTable()
+			table.Parameters["transient_lastDdlTime"] = "1700000000"
+			require.NoError(t, f.CreateTable("pls", table))
+			require.Error(t, f.CreateTable("pls", table))
+			require.FileExists(t, filepath.Join(root, "pls", "events."+format))
+
+			got, err := f.GetTableInfo("pls", "events")
+			require.NoError(t, err)
+			require.Equal(t, fileTestTable(), got)
+
+			table.Description = "events"
+			require.NoError(t, f.AlterTable("pls", table))
+			got, err = f.GetTableInfo("pls", "events")
+			require.NoError(t, err)
+			require.Equal(t, "events", got.Description)
+
+			databases, err := f.GetDatabases()
+			require.NoError(t, err)
+			require.Equal(t, []string{"pls"}, databases)
+			database, err := f.GetDatabaseInfo("pls")
+			require.NoError(t, err)
+			require.Equal(t, model.Database{Name: "pls", Description: "places", Location: "s3://bucket/pls"}, database)
+			tables, err := f.GetTables("pls")
+			require.NoError(t, err)
+			require.Equal(t, []string{"events"}, tables)
+		})
+	}
+}
+
+func TestFileMetaStore_KeepsEmptyParameterValues(t *testing.T) {
+	for _, format := range []string{FileFormatYaml, FileFormatJson} {
+		t.Run(format, func(t *testing.T) {
+			f, err := NewFileMetaStore(t.TempDir(), format, nil)
+			require.NoError(t, err)
+			require.NoError(t, f.CreateDatabase(model.Database{Name: "pls"}))
+			table := fileTestTable()
+			table.Description = ""
+			table.Parameters["comment"] = ""
+			table.SerdeParameters = map[string]string{"serialization.null.format": ""}
+			table.Columns[0].Parameters = map[string]string{"pii": ""}
+			require.NoError(t, f.CreateTable("pls", table))
+
+			got, err := f.GetTableInfo("pls", "events")
+			require.NoError(t, err)
+			require.Equal(t, table, got)
+		})
+	}
+}
+
+func TestFileMetaStore_ReadHandWritten(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "pls"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".git"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "pls", "visits.yaml"), []byte(`
+format: parquet
+columns:
+  - name: id
+    type:
+      sql_type: bigint
+parameters:
+  classification: parquet
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "pls", "README.md"), []byte("tables"), 0644))
+	f, err := NewFileMetaStore(root, "", nil)
+	require.NoError(t, err)
+
+	databases, err := f.GetDatabases()
+	require.NoError(t, err)
+	require.Equal(t, []string{"pls"}, databases)
+	database, err := f.GetDatabaseInfo("pls")
+	require.NoError(t, err)
+	require.Equal(t, model.Database{Name: "pls"}, database)
+	tables, err := f.GetTables("pls")
+	require.NoError(t, err)
+	require.Equal(t, []string{"visits"}, tables)
+	got, err := f.GetTableInfo("pls", "visits")
+	require.NoError(t, err)
+	require.Equal(t, model.TableInfo{
+		Name:       "visits",
+		Columns:    []model.Column{{Name: "id", Type: model.ColumnType{SqlType: model.BIGINT}}},
+		Format:     model.PARQUET,
+		Parameters: map[string]string{"classification": "parquet"},
+	}, got)
+}
+
+func TestFileMetaStore_ReadUnquotedScalars(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "pls"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "pls", "visits.yaml"), []byte(`
+format: textfile
+columns:
+  - name: id
+    type:
+      sql_type: bigint
+    parameters:
+      nullable: false
+parameters:
+  EXTERNAL: TRUE
+  numFiles: 10
+  totalSize: 1.5
+serde_parameters:
+  serialization.format: 1
+skewed:
+  columns: [id]
+  values: [[1], [2]]
+`), 0644))
+	f, err := NewFileMetaStore(root, "", nil)
+	require.NoError(t, err)
+
+	got, err := f.GetTableInfo("pls", "visits")
+	require.NoError(t, err)
+	require.Equal(t, model.TableInfo{
+		Name: "visits",
+		Columns: []model.Column{{
+			Name:       "id",
+			Type:       model.ColumnType{SqlType: model.BIGINT},
+			Parameters: map[string]string{"nullable": "false"},
+		}},
+		Format:          model.TableFormat(model.TEXTFILE),
+		Parameters:      map[string]string{"EXTERNAL": "TRUE", "numFiles": "10", "totalSize": "1.5"},
+		SerdeParameters: map[string]string{"serialization.format": "1"},
+		Skewed:          &model.SkewedInfo{Columns: []string{"id"}, Values: [][]string{{"1"}, {"2"}}},
+	}, got)
+}
+
+func TestFileMetaStore_DropDatabase(t *testing.T) {
+	tests := []struct {
+		name    string
+		cascade bool
+		wantErr bool
+	}{
+		{
+			name:    "shouldFailNotEmpty",
+			wantErr: true,
+		},
+		{
+			name:    "shouldDropCascade",
+			cascade: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			f, err := NewFileMetaStore(root, FileFormatYaml, nil)
+			require.NoError(t, err)
+			require.NoError(t, f.CreateDatabase(model.Database{Name: "pls"}))
+			require.NoError(t, f.CreateTable("pls", fileTestTable()))
+			err = f.DropDatabase("pls", tt.cascade)
+			if tt.wantErr {
+				require.Error(t, err)
+				require.DirExists(t, filepath.Join(root, "pls"))
+				return
+			}
+			require.NoError(t, err)
+			require.NoDirExists(t, filepath.Join(root, "pls"))
+			require.NoFileExists(t, filepath.Join(root, "pls.yaml"))
+		})
+	}
+}
+
+func TestFileMetaStore_DropTable(t *testing.T) {
+	root := t.TempDir()
+	f, err := NewFileMetaStore(root, FileFormatYaml, nil)
+	require.NoError(t, err)
+	require.NoError(t, f.CreateDatabase(model.Database{Name: "pls"}))
+	require.NoError(t, f.CreateTable("pls", fileTestTable()))
+	require.NoError(t, f.DropTable("pls", "events", true))
+	require.NoFileExists(t, filepath.Join(root, "pls", "events.yaml"))
+	require.NoError(t, f.DropTable("pls", "events", false))
+}
+
+func TestFileMetaStore_Partitions(t *testing.T) {
+	f, err := NewFileMetaStore(t.TempDir(), FileFormatYaml, nil)
+	require.NoError(t, err)
+	require.NoError(t, f.AddPartitions("pls", "events", []model.Partition{{Values: []string{"2024-01-01"}}}))
+	partitions, err := f.GetPartitions("pls", "events")
+	require.NoError(t, err)
+	require.Empty(t, partitions)
+}
+
+func TestFileMetaStore_InvalidNames(t *testing.T) {
+	f, err := NewFileMetaStore(t.TempDir(), FileFormatYaml, nil)
+	require.NoError(t, err)
+	require.Error(t, f.CreateDatabase(model.Database{Name: "../pls"}))
+	_, err = f.GetTableInfo("pls", "../../etc/passwd")
+	require.Error(t, err)
+	_, err = NewFileMetaStore(t.TempDir(), "toml", nil)
+	require.Error(t, err)
+}
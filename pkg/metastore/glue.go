@@ -2,21 +2,99 @@ package metastore
 
 import (
 	"context"
+	"fmt"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/glue"
 	"github.com/aws/aws-sdk-go/service/glue/glueiface"
+	"github.com/hashicorp/go-multierror"
 	"github.com/sirupsen/logrus"
 	"github.com/the-Data-Appeal-Company/metaman/pkg/deleter"
 	"github.com/the-Data-Appeal-Company/metaman/pkg/model"
+	"strings"
+)
+
+const (
+	glueCreatePartitionBatchSize = 100
+	glueDeletePartitionBatchSize = 25
 )
 
 type GlueMetaStore struct {
-	glue        glueiface.GlueAPI
-	fileDeleter deleter.FileDeleter
+	glue               glueiface.GlueAPI
+	catalogId          string
+	fileDeleter        deleter.FileDeleter
+	excludedParameters []string
+}
+
+// NewGlueMetaStore manages the glue catalog of the catalogId account, the caller's own when empty.
+func NewGlueMetaStore(glue glueiface.GlueAPI, catalogId string, fileDeleter deleter.FileDeleter, excludedParameters []string) *GlueMetaStore {
+	return &GlueMetaStore{glue: glue, catalogId: catalogId, fileDeleter: fileDeleter, excludedParameters: excludedParameters}
+}
+
+func (g *GlueMetaStore) GetDatabases() ([]string, error) {
+	dbs := make([]string, 0)
+	hasNextToken := true
+	var nextToken *string
+	for hasNextToken {
+		databases, err := g.glue.GetDatabases(&glue.GetDatabasesInput{
+			CatalogId: ptrFromString(g.catalogId),
+			NextToken: nextToken,
+		})
+		if err != nil {
+			return nil, err
+		}
+		hasNextToken = databases.NextToken != nil
+		nextToken = databases.NextToken
+		for _, database := range databases.DatabaseList {
+			dbs = append(dbs, *database.Name)
+		}
+	}
+	return dbs, nil
+}
+
+func (g *GlueMetaStore) GetDatabaseInfo(dbName string) (model.Database, error) {
+	db, err := g.glue.GetDatabase(&glue.GetDatabaseInput{
+		CatalogId: ptrFromString(g.catalogId),
+		Name:      &dbName,
+	})
+	if err != nil {
+		return model.Database{}, err
+	}
+	return model.Database{
+		Name:        stringFromPtr(db.Database.Name),
+		Description: stringFromPtr(db.Database.Description),
+		Location:    stringFromPtr(db.Database.LocationUri),
+		Parameters:  aws.StringValueMap(db.Database.Parameters),
+	}, nil
 }
 
-func NewGlueMetaStore(glue glueiface.GlueAPI, fileDeleter deleter.FileDeleter) *GlueMetaStore {
-	return &GlueMetaStore{glue: glue, fileDeleter: fileDeleter}
+func (g *GlueMetaStore) CreateDatabase(database model.Database) error {
+	_, err := g.glue.CreateDatabase(&glue.CreateDatabaseInput{
+		CatalogId: ptrFromString(g.catalogId),
+		DatabaseInput: &glue.DatabaseInput{
+			Name:        aws.String(database.Name),
+			Description: ptrFromString(database.Description),
+			LocationUri: ptrFromString(convertS3Format(GLUE, database.Location)),
+			Parameters:  mapParametersGlue(database.Parameters),
+		},
+	})
+	return err
+}
+
+func (g *GlueMetaStore) DropDatabase(dbName string, cascade bool) error {
+	if !cascade {
+		tables, err := g.GetTables(dbName)
+		if err != nil {
+			return err
+		}
+		if len(tables) > 0 {
+			return fmt.Errorf("database %s is not empty", dbName)
+		}
+	}
+	_, err := g.glue.DeleteDatabase(&glue.DeleteDatabaseInput{
+		CatalogId: ptrFromString(g.catalogId),
+		Name:      aws.String(dbName),
+	})
+	return err
 }
 
 func (g *GlueMetaStore) GetTables(dbName string) ([]string, error) {
@@ -25,6 +103,7 @@ func (g *GlueMetaStore) GetTables(dbName string) ([]string, error) {
 	var nextToken *string
 	for hasNextToken {
 		tables, err := g.glue.GetTables(&glue.GetTablesInput{
+			CatalogId:    ptrFromString(g.catalogId),
 			NextToken:    nextToken,
 			DatabaseName: &dbName,
 		})
@@ -42,37 +121,93 @@ func (g *GlueMetaStore) GetTables(dbName string) ([]string, error) {
 
 func (g *GlueMetaStore) GetTableInfo(dbName, tableName string) (model.TableInfo, error) {
 	table, err := g.glue.GetTable(&glue.GetTableInput{
+		CatalogId:    ptrFromString(g.catalogId),
 		DatabaseName: &dbName,
 		Name:         &tableName,
 	})
 	if err != nil {
 		return model.TableInfo{}, err
 	}
+	serde := unmapSerdeInfoGlue(table.Table.StorageDescriptor.SerdeInfo)
+	format := model.FromTable(stringFromPtr(table.Table.StorageDescriptor.InputFormat), stringFromPtr(table.Table.StorageDescriptor.Location), aws.StringValueMap(table.Table.Parameters), serde)
+	location := tableLocation(format, stringFromPtr(table.Table.StorageDescriptor.Location))
+	if metadataLocation := stringFromPtr(table.Table.Parameters[model.IcebergMetadataLocation]); format == model.ICEBERG && metadataLocation != "" {
+		location = metadataLocation
+	}
+	var storage *model.Storage
+	if format == model.UNKNOWN {
+		storage = &model.Storage{
+			InputFormat:      stringFromPtr(table.Table.StorageDescriptor.InputFormat),
+			OutputFormat:     stringFromPtr(table.Table.StorageDescriptor.OutputFormat),
+			SerializationLib: serde.SerializationLib,
+			TableType:        stringFromPtr(table.Table.TableType),
+		}
+	}
 	return model.TableInfo{
 		Name:             stringFromPtr(table.Table.Name),
 		Columns:          mapColumnsGlue(table.Table.StorageDescriptor.Columns),
 		Partitions:       mapColumnsGlue(table.Table.PartitionKeys),
-		MetadataLocation: stringFromPtr(table.Table.StorageDescriptor.Location),
-		Format:           model.FromInputOutput(stringFromPtr(table.Table.StorageDescriptor.InputFormat)),
+		MetadataLocation: location,
+		Format:           format,
+		Parameters:       filterParameters(aws.StringValueMap(table.Table.Parameters), g.excludedParameters),
+		Description:      stringFromPtr(table.Table.Description),
+		Owner:            stringFromPtr(table.Table.Owner),
+		SerdeParameters:  serde.Parameters,
+		Storage:          storage,
+		Bucketing:        mapBucketingGlue(table.Table.StorageDescriptor),
+		Skewed:           mapSkewedInfoGlue(table.Table.StorageDescriptor.SkewedInfo),
 	}, nil
 }
 
 func (g *GlueMetaStore) CreateTable(dbName string, table model.TableInfo) error {
+	if err := validateColumnTypes(table); err != nil {
+		return err
+	}
 	_, err := g.glue.CreateTable(&glue.CreateTableInput{
+		CatalogId:    ptrFromString(g.catalogId),
 		DatabaseName: &dbName,
-		TableInput: &glue.TableInput{
-			Name: &table.Name,
-			StorageDescriptor: &glue.StorageDescriptor{
-				Columns:      unmapColumnsGlue(table.Columns),
-				InputFormat:  aws.String(table.Format.InputFormat()),
-				Location:     aws.String(getMetadataLocation(GLUE, table)),
-				OutputFormat: aws.String(table.Format.OutputFormat()),
-				SerdeInfo:    mapSerdeInfoGlue(table.Format.SerDeInfo()),
-			},
-			PartitionKeys: unmapColumnsGlue(table.Partitions),
-			TableType:     aws.String(table.Format.TableType()),
-			Parameters:    mapParametersGlue(table.Format.Parameters(convertS3Format(GLUE, table.MetadataLocation))),
-		},
+		TableInput:   buildTableInputGlue(table),
+	})
+	return err
+}
+
+// AlterTable updates the table keeping the current table and column parameters it doesn't set,
+// as well as the current description and owner when it has none.
+func (g *GlueMetaStore) AlterTable(dbName string, table model.TableInfo) error {
+	if err := validateColumnTypes(table); err != nil {
+		return err
+	}
+	current, err := g.glue.GetTable(&glue.GetTableInput{
+		CatalogId:    ptrFromString(g.catalogId),
+		DatabaseName: &dbName,
+		Name:         aws.String(table.Name),
+	})
+	if err != nil {
+		return err
+	}
+	input := buildTableInputGlue(table)
+	currentParameters := aws.StringValueMap(current.Table.Parameters)
+	input.Parameters = mapParametersGlue(mergeParameters(currentParameters, tableParameters(GLUE, table)))
+	if current.Table.StorageDescriptor != nil {
+		keepColumnParametersGlue(current.Table.StorageDescriptor.Columns, input.StorageDescriptor.Columns)
+	}
+	keepColumnParametersGlue(current.Table.PartitionKeys, input.PartitionKeys)
+	if input.Description == nil {
+		input.Description = current.Table.Description
+	}
+	if input.Owner == nil {
+		input.Owner = current.Table.Owner
+	}
+	if table.Format == model.ICEBERG {
+		previous := previousMetadataLocation(currentParameters[model.IcebergMetadataLocation], stringFromPtr(input.Parameters[model.IcebergMetadataLocation]))
+		if previous != "" {
+			input.Parameters[model.IcebergPreviousMetadataLocation] = aws.String(previous)
+		}
+	}
+	_, err = g.glue.UpdateTable(&glue.UpdateTableInput{
+		CatalogId:    ptrFromString(g.catalogId),
+		DatabaseName: &dbName,
+		TableInput:   input,
 	})
 	return err
 }
@@ -86,6 +221,7 @@ func (g *GlueMetaStore) DropTable(dbName string, tableName string, deleteData bo
 		return err
 	}
 	_, err = g.glue.DeleteTable(&glue.DeleteTableInput{
+		CatalogId:    ptrFromString(g.catalogId),
 		DatabaseName: aws.String(dbName),
 		Name:         aws.String(tableName),
 	})
@@ -93,8 +229,8 @@ func (g *GlueMetaStore) DropTable(dbName string, tableName string, deleteData bo
 		return err
 	}
 	if deleteData {
-		if isOnS3(info.MetadataLocation) {
-			bucket, path := getBucketPath(info.MetadataLocation)
+		if location := dataLocation(info); isOnS3(location) {
+			bucket, path := getBucketPath(location)
 			err := g.fileDeleter.Delete(context.Background(), bucket, path)
 			if err != nil {
 				logrus.Errorf("table dropped on glue but could not delete files if they are on s3")
@@ -105,12 +241,147 @@ func (g *GlueMetaStore) DropTable(dbName string, tableName string, deleteData bo
 	return nil
 }
 
+func (g *GlueMetaStore) GetPartitions(dbName, tableName string) ([]model.Partition, error) {
+	partitions := make([]model.Partition, 0)
+	hasNextToken := true
+	var nextToken *string
+	for hasNextToken {
+		parts, err := g.glue.GetPartitions(&glue.GetPartitionsInput{
+			CatalogId:    ptrFromString(g.catalogId),
+			NextToken:    nextToken,
+			DatabaseName: &dbName,
+			TableName:    &tableName,
+		})
+		if err != nil {
+			return nil, err
+		}
+		hasNextToken = parts.NextToken != nil
+		nextToken = parts.NextToken
+		for _, part := range parts.Partitions {
+			var location string
+			if part.StorageDescriptor != nil {
+				location = stringFromPtr(part.StorageDescriptor.Location)
+			}
+			partitions = append(partitions, model.Partition{
+				Values:   aws.StringValueSlice(part.Values),
+				Location: location,
+			})
+		}
+	}
+	return partitions, nil
+}
+
+func (g *GlueMetaStore) AddPartitions(dbName, tableName string, partitions []model.Partition) error {
+	if len(partitions) == 0 {
+		return nil
+	}
+	table, err := g.glue.GetTable(&glue.GetTableInput{
+		CatalogId:    ptrFromString(g.catalogId),
+		DatabaseName: &dbName,
+		Name:         &tableName,
+	})
+	if err != nil {
+		return err
+	}
+	var result error
+	for start := 0; start < len(partitions); start += glueCreatePartitionBatchSize {
+		end := batchEnd(start, glueCreatePartitionBatchSize, len(partitions))
+		inputs := make([]*glue.PartitionInput, end-start)
+		for i, partition := range partitions[start:end] {
+			sd := *table.Table.StorageDescriptor
+			sd.Location = aws.String(convertS3Format(GLUE, partition.Location))
+			inputs[i] = &glue.PartitionInput{
+				Values:            aws.StringSlice(partition.Values),
+				StorageDescriptor: &sd,
+			}
+		}
+		out, err := g.glue.BatchCreatePartition(&glue.BatchCreatePartitionInput{
+			CatalogId:          ptrFromString(g.catalogId),
+			DatabaseName:       &dbName,
+			TableName:          &tableName,
+			PartitionInputList: inputs,
+		})
+		if err != nil {
+			return err
+		}
+		result = appendPartitionErrorsGlue(result, out.Errors)
+	}
+	return result
+}
+
+func (g *GlueMetaStore) DropPartitions(dbName, tableName string, partitions []model.Partition) error {
+	var result error
+	for start := 0; start < len(partitions); start += glueDeletePartitionBatchSize {
+		end := batchEnd(start, glueDeletePartitionBatchSize, len(partitions))
+		values := make([]*glue.PartitionValueList, end-start)
+		for i, partition := range partitions[start:end] {
+			values[i] = &glue.PartitionValueList{
+				Values: aws.StringSlice(partition.Values),
+			}
+		}
+		out, err := g.glue.BatchDeletePartition(&glue.BatchDeletePartitionInput{
+			CatalogId:          ptrFromString(g.catalogId),
+			DatabaseName:       &dbName,
+			TableName:          &tableName,
+			PartitionsToDelete: values,
+		})
+		if err != nil {
+			return err
+		}
+		result = appendPartitionErrorsGlue(result, out.Errors)
+	}
+	return result
+}
+
+func appendPartitionErrorsGlue(result error, errors []*glue.PartitionError) error {
+	for _, partitionError := range errors {
+		var message string
+		if partitionError.ErrorDetail != nil {
+			message = fmt.Sprintf("%s: %s", stringFromPtr(partitionError.ErrorDetail.ErrorCode), stringFromPtr(partitionError.ErrorDetail.ErrorMessage))
+		}
+		result = multierror.Append(result, fmt.Errorf("partition %v: %s", aws.StringValueSlice(partitionError.PartitionValues), message))
+	}
+	return result
+}
+
+func buildTableInputGlue(table model.TableInfo) *glue.TableInput {
+	sd := &glue.StorageDescriptor{
+		Columns:      unmapColumnsGlue(table.Columns),
+		InputFormat:  aws.String(table.InputFormat()),
+		Location:     aws.String(getMetadataLocation(GLUE, table)),
+		OutputFormat: aws.String(table.OutputFormat()),
+		SerdeInfo:    mapSerdeInfoGlue(serDeInfo(GLUE, table)),
+		SkewedInfo:   unmapSkewedInfoGlue(table.Skewed),
+	}
+	if table.Bucketing != nil {
+		sd.NumberOfBuckets = aws.Int64(int64(table.Bucketing.NumBuckets))
+		sd.BucketColumns = aws.StringSlice(table.Bucketing.Columns)
+		sd.SortColumns = make([]*glue.Order, len(table.Bucketing.SortColumns))
+		for i, column := range table.Bucketing.SortColumns {
+			sd.SortColumns[i] = &glue.Order{Column: aws.String(column.Name), SortOrder: aws.Int64(int64(sortOrder(column.Ascending)))}
+		}
+	}
+	return &glue.TableInput{
+		Name:              aws.String(table.Name),
+		StorageDescriptor: sd,
+		PartitionKeys:     unmapColumnsGlue(table.Partitions),
+		TableType:         aws.String(table.TableType()),
+		Parameters:        mapParametersGlue(tableParameters(GLUE, table)),
+		Description:       ptrFromString(table.Description),
+		Owner:             ptrFromString(table.Owner),
+	}
+}
+
 func mapColumnsGlue(columns []*glue.Column) []model.Column {
 	cols := make([]model.Column, len(columns))
 	for i, column := range columns {
 		cols[i] = model.Column{
-			Name: stringFromPtr(column.Name),
-			Type: model.MapColumnType(stringFromPtr(column.Type)),
+			Name:    stringFromPtr(column.Name),
+			Type:    model.ReadColumnType(stringFromPtr(column.Type)),
+			Comment: stringFromPtr(column.Comment),
+		}
+		if len(column.Parameters) > 0 {
+			cols[i].Parameters = aws.StringValueMap(column.Parameters)
 		}
 	}
 	return cols
@@ -120,13 +391,32 @@ func unmapColumnsGlue(columns []model.Column) []*glue.Column {
 	cols := make([]*glue.Column, len(columns))
 	for i := range columns {
 		cols[i] = &glue.Column{
-			Name: &columns[i].Name,
-			Type: aws.String(model.UnmapColumnType(columns[i].Type)),
+			Name:    &columns[i].Name,
+			Type:    aws.String(model.UnmapColumnType(columns[i].Type)),
+			Comment: ptrFromString(columns[i].Comment),
+		}
+		if len(columns[i].Parameters) > 0 {
+			cols[i].Parameters = mapParametersGlue(columns[i].Parameters)
 		}
 	}
 	return cols
 }
 
+// keepColumnParametersGlue merges the parameters of the current columns under the ones of the altered columns
+// with the same name, e.g. the iceberg.field.* ones set by athena or spark.
+func keepColumnParametersGlue(current, altered []*glue.Column) {
+	parameters := make(map[string]map[string]*string, len(current))
+	for _, column := range current {
+		parameters[stringFromPtr(column.Name)] = column.Parameters
+	}
+	for _, column := range altered {
+		currentParameters := parameters[stringFromPtr(column.Name)]
+		if len(currentParameters) > 0 {
+			column.Parameters = mapParametersGlue(mergeParameters(aws.StringValueMap(currentParameters), aws.StringValueMap(column.Parameters)))
+		}
+	}
+}
+
 func mapParametersGlue(parameters map[string]string) map[string]*string {
 	params := make(map[string]*string)
 	for k, v := range parameters {
@@ -145,3 +435,63 @@ func mapSerdeInfoGlue(info *model.SerDeInfo) *glue.SerDeInfo {
 		Parameters:           mapParametersGlue(info.Parameters),
 	}
 }
+
+func unmapSerdeInfoGlue(info *glue.SerDeInfo) *model.SerDeInfo {
+	if info == nil {
+		return &model.SerDeInfo{}
+	}
+	serde := &model.SerDeInfo{SerializationLib: stringFromPtr(info.SerializationLibrary)}
+	if len(info.Parameters) > 0 {
+		serde.Parameters = aws.StringValueMap(info.Parameters)
+	}
+	return serde
+}
+
+func mapBucketingGlue(sd *glue.StorageDescriptor) *model.Bucketing {
+	if len(sd.BucketColumns) == 0 && len(sd.SortColumns) == 0 {
+		return nil
+	}
+	bucketing := &model.Bucketing{
+		Columns:     aws.StringValueSlice(sd.BucketColumns),
+		NumBuckets:  int(aws.Int64Value(sd.NumberOfBuckets)),
+		SortColumns: make([]model.SortColumn, len(sd.SortColumns)),
+	}
+	for i, order := range sd.SortColumns {
+		bucketing.SortColumns[i] = model.SortColumn{Name: stringFromPtr(order.Column), Ascending: aws.Int64Value(order.SortOrder) == sortAscending}
+	}
+	return bucketing
+}
+
+// skewedValueSeparator joins the values of a skewed value tuple kept as a single string,
+// as glue does for skewed values and location map keys.
+const skewedValueSeparator = ","
+
+func mapSkewedInfoGlue(info *glue.SkewedInfo) *model.SkewedInfo {
+	if info == nil || len(info.SkewedColumnNames) == 0 {
+		return nil
+	}
+	values := make([][]string, len(info.SkewedColumnValues))
+	for i, value := range info.SkewedColumnValues {
+		values[i] = strings.Split(stringFromPtr(value), skewedValueSeparator)
+	}
+	return &model.SkewedInfo{
+		Columns:   aws.StringValueSlice(info.SkewedColumnNames),
+		Values:    values,
+		Locations: aws.StringValueMap(info.SkewedColumnValueLocationMaps),
+	}
+}
+
+func unmapSkewedInfoGlue(info *model.SkewedInfo) *glue.SkewedInfo {
+	if info == nil {
+		return nil
+	}
+	values := make([]*string, len(info.Values))
+	for i, value := range info.Values {
+		values[i] = aws.String(strings.Join(value, skewedValueSeparator))
+	}
+	return &glue.SkewedInfo{
+		SkewedColumnNames:             aws.StringSlice(info.Columns),
+		SkewedColumnValues:            values,
+		SkewedColumnValueLocationMaps: mapParametersGlue(info.Locations),
+	}
+}
@@ -14,24 +14,81 @@ import (
 
 type GlueMock struct {
 	glueiface.GlueAPI
-	getTableError error
-	createCalls   []*glue.CreateTableInput
-	deleteCalls   []*glue.DeleteTableInput
+	getTableError        error
+	getTableOut          *glue.TableData
+	createCalls          []*glue.CreateTableInput
+	updateCalls          []*glue.UpdateTableInput
+	deleteCalls          []*glue.DeleteTableInput
+	createPartitionCalls []*glue.BatchCreatePartitionInput
+	deletePartitionCalls []*glue.BatchDeletePartitionInput
+	createDbCalls        []*glue.CreateDatabaseInput
+	deleteDbCalls        []*glue.DeleteDatabaseInput
+	readCatalogIds       []*string
+}
+
+func (g *GlueMock) GetDatabases(input *glue.GetDatabasesInput) (*glue.GetDatabasesOutput, error) {
+	g.readCatalogIds = append(g.readCatalogIds, input.CatalogId)
+	var nextToken *string
+	name := "default"
+	if input.NextToken == nil {
+		nextToken = aws.String("token")
+	} else {
+		name = "pls"
+	}
+	return &glue.GetDatabasesOutput{
+		NextToken: nextToken,
+		DatabaseList: []*glue.Database{
+			{Name: aws.String(name)},
+		},
+	}, nil
+}
+
+func (g *GlueMock) GetDatabase(input *glue.GetDatabaseInput) (*glue.GetDatabaseOutput, error) {
+	g.readCatalogIds = append(g.readCatalogIds, input.CatalogId)
+	if *input.Name != "pls" {
+		return nil, &glue.EntityNotFoundException{}
+	}
+	return &glue.GetDatabaseOutput{
+		Database: &glue.Database{
+			Name:        aws.String("pls"),
+			Description: aws.String("pls database"),
+			LocationUri: aws.String("s3://bucket/pls"),
+			Parameters:  aws.StringMap(map[string]string{"team": "data"}),
+		},
+	}, nil
+}
+
+func (g *GlueMock) CreateDatabase(input *glue.CreateDatabaseInput) (*glue.CreateDatabaseOutput, error) {
+	g.createDbCalls = append(g.createDbCalls, input)
+	if *input.DatabaseInput.Name == "err" {
+		return nil, fmt.Errorf("error")
+	}
+	return &glue.CreateDatabaseOutput{}, nil
+}
+
+func (g *GlueMock) DeleteDatabase(input *glue.DeleteDatabaseInput) (*glue.DeleteDatabaseOutput, error) {
+	g.deleteDbCalls = append(g.deleteDbCalls, input)
+	return &glue.DeleteDatabaseOutput{}, nil
 }
 
 func (g *GlueMock) GetTable(input *glue.GetTableInput) (*glue.GetTableOutput, error) {
+	g.readCatalogIds = append(g.readCatalogIds, input.CatalogId)
 	if g.getTableError != nil {
 		return nil, g.getTableError
 	}
 	if *input.DatabaseName != "pls" || (*input.Name != "table" && *input.Name != "table1") {
 		return nil, fmt.Errorf("error")
 	}
+	if g.getTableOut != nil {
+		return &glue.GetTableOutput{Table: g.getTableOut}, nil
+	}
 	return &glue.GetTableOutput{
 		Table: getTableData(input.DatabaseName),
 	}, nil
 }
 
 func (g *GlueMock) GetTables(input *glue.GetTablesInput) (*glue.GetTablesOutput, error) {
+	g.readCatalogIds = append(g.readCatalogIds, input.CatalogId)
 	if *input.DatabaseName != "pls" {
 		return nil, fmt.Errorf("error")
 	}
@@ -51,6 +108,14 @@ func (g *GlueMock) CreateTable(input *glue.CreateTableInput) (*glue.CreateTableO
 	return &glue.CreateTableOutput{}, nil
 }
 
+func (g *GlueMock) UpdateTable(input *glue.UpdateTableInput) (*glue.UpdateTableOutput, error) {
+	g.updateCalls = append(g.updateCalls, input)
+	if *input.DatabaseName != "pls" {
+		return nil, fmt.Errorf("error")
+	}
+	return &glue.UpdateTableOutput{}, nil
+}
+
 func (g *GlueMock) DeleteTable(input *glue.DeleteTableInput) (*glue.DeleteTableOutput, error) {
 	g.deleteCalls = append(g.deleteCalls, input)
 	if *input.DatabaseName != "pls" || *input.Name == "table1" {
@@ -59,6 +124,56 @@ func (g *GlueMock) DeleteTable(input *glue.DeleteTableInput) (*glue.DeleteTableO
 	return &glue.DeleteTableOutput{}, nil
 }
 
+func (g *GlueMock) GetPartitions(input *glue.GetPartitionsInput) (*glue.GetPartitionsOutput, error) {
+	g.readCatalogIds = append(g.readCatalogIds, input.CatalogId)
+	if *input.DatabaseName != "pls" {
+		return nil, fmt.Errorf("error")
+	}
+	var nextToken *string
+	value := "1"
+	if input.NextToken == nil {
+		nextToken = aws.String("token")
+	} else {
+		value = "2"
+	}
+	return &glue.GetPartitionsOutput{
+		NextToken: nextToken,
+		Partitions: []*glue.Partition{
+			{
+				Values: aws.StringSlice([]string{value}),
+				StorageDescriptor: &glue.StorageDescriptor{
+					Location: aws.String("s3://bucket/table/partition=" + value),
+				},
+			},
+		},
+	}, nil
+}
+
+func (g *GlueMock) BatchCreatePartition(input *glue.BatchCreatePartitionInput) (*glue.BatchCreatePartitionOutput, error) {
+	g.createPartitionCalls = append(g.createPartitionCalls, input)
+	out := &glue.BatchCreatePartitionOutput{}
+	for _, partition := range input.PartitionInputList {
+		if *partition.Values[0] == "err" {
+			out.Errors = append(out.Errors, &glue.PartitionError{
+				PartitionValues: partition.Values,
+				ErrorDetail: &glue.ErrorDetail{
+					ErrorCode:    aws.String("AlreadyExistsException"),
+					ErrorMessage: aws.String("partition already exists"),
+				},
+			})
+		}
+	}
+	return out, nil
+}
+
+func (g *GlueMock) BatchDeletePartition(input *glue.BatchDeletePartitionInput) (*glue.BatchDeletePartitionOutput, error) {
+	g.deletePartitionCalls = append(g.deletePartitionCalls, input)
+	if *input.DatabaseName != "pls" {
+		return nil, fmt.Errorf("error")
+	}
+	return &glue.BatchDeletePartitionOutput{}, nil
+}
+
 type GlueMockGetTablesPaginated struct {
 	glueiface.GlueAPI
 }
@@ -104,8 +219,10 @@ func getTableData(dbName *string) *awsGlue.TableData {
 		StorageDescriptor: &glue.StorageDescriptor{
 			Columns: []*glue.Column{
 				{
-					Name: aws.String("id"),
-					Type: aws.String("bigint"),
+					Name:       aws.String("id"),
+					Type:       aws.String("bigint"),
+					Comment:    aws.String("primary key"),
+					Parameters: aws.StringMap(map[string]string{"pii": "false"}),
 				},
 				{
 					Name: aws.String("sign"),
@@ -141,7 +258,12 @@ func getTableData(dbName *string) *awsGlue.TableData {
 			Location:     aws.String("s3://bucket/table"),
 			OutputFormat: aws.String("org.apache.hadoop.hiveFactory.ql.io.parquet.MapredParquetOutputFormat"),
 		},
-		TableType: aws.String("EXTERNAL_TABLE"),
+		TableType:   aws.String("EXTERNAL_TABLE"),
+		Description: aws.String("table comment"),
+		Parameters: aws.StringMap(map[string]string{
+			"classification":        "parquet",
+			"transient_lastDdlTime": "1644329244",
+		}),
 	}
 }
 
@@ -235,8 +357,10 @@ func TestGlueMetaStore_GetTableInfo(t *testing.T) {
 				Name: "table",
 				Columns: []model.Column{
 					{
-						Name: "id",
-						Type: model.ColumnType{SqlType: model.BIGINT},
+						Name:       "id",
+						Type:       model.ColumnType{SqlType: model.BIGINT},
+						Comment:    "primary key",
+						Parameters: map[string]string{"pii": "false"},
 					},
 					{
 						Name: "sign",
@@ -278,6 +402,9 @@ func TestGlueMetaStore_GetTableInfo(t *testing.T) {
 				},
 				MetadataLocation: "s3://bucket/table",
 				Format:           model.PARQUET,
+				Parameters:       map[string]string{"classification": "parquet"},
+				Description:      "table comment",
+				Owner:            "sap",
 			},
 			wantErr: false,
 		},
@@ -297,7 +424,8 @@ func TestGlueMetaStore_GetTableInfo(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			g := &GlueMetaStore{
-				glue: tt.fields.glue,
+				glue:               tt.fields.glue,
+				excludedParameters: []string{"transient_lastDdlTime"},
 			}
 			got, err := g.GetTableInfo(tt.args.dbName, tt.args.tableName)
 			if (err != nil) != tt.wantErr {
@@ -338,8 +466,10 @@ func TestGlueMetaStore_CreateTable(t *testing.T) {
 							Type: model.ColumnType{SqlType: model.BIGINT},
 						},
 						{
-							Name: "name",
-							Type: model.ColumnType{SqlType: model.VARCHAR, Length: 200},
+							Name:       "name",
+							Type:       model.ColumnType{SqlType: model.VARCHAR, Length: 200},
+							Comment:    "customer name",
+							Parameters: map[string]string{"pii": "true"},
 						},
 					},
 					Partitions: []model.Column{
@@ -400,6 +530,11 @@ func TestGlueMetaStore_CreateTable(t *testing.T) {
 			for i, column := range mock.createCalls[0].TableInput.StorageDescriptor.Columns {
 				require.Equal(t, tt.args.table.Columns[i].Name, *column.Name)
 				require.Equal(t, model.UnmapColumnType(tt.args.table.Columns[i].Type), *column.Type)
+				require.Equal(t, tt.args.table.Columns[i].Comment, aws.StringValue(column.Comment))
+				require.Equal(t, len(tt.args.table.Columns[i].Parameters), len(column.Parameters))
+				for k, v := range tt.args.table.Columns[i].Parameters {
+					require.Equal(t, v, *column.Parameters[k])
+				}
 			}
 			require.Len(t, mock.createCalls[0].TableInput.PartitionKeys, len(tt.args.table.Partitions))
 			for i, column := range mock.createCalls[0].TableInput.PartitionKeys {
@@ -410,6 +545,160 @@ func TestGlueMetaStore_CreateTable(t *testing.T) {
 	}
 }
 
+func TestGlueMetaStore_CreateTableParameters(t *testing.T) {
+	mock := &GlueMock{}
+	g := &GlueMetaStore{
+		glue: mock,
+	}
+	table := model.TableInfo{
+		Name: "table",
+		Columns: []model.Column{
+			{
+				Name: "id",
+				Type: model.ColumnType{SqlType: model.BIGINT},
+			},
+		},
+		MetadataLocation: "s3://bucket/table",
+		Format:           model.PARQUET,
+		Parameters:       map[string]string{"classification": "parquet"},
+		Description:      "table comment",
+		Owner:            "sap",
+	}
+	require.NoError(t, g.CreateTable("pls", table))
+	table.Name = "table2"
+	table.Parameters = nil
+	table.Description = ""
+	table.Owner = ""
+	require.NoError(t, g.CreateTable("pls", table))
+
+	require.Len(t, mock.createCalls, 2)
+	input := mock.createCalls[0].TableInput
+	require.Equal(t, "sap", *input.Owner)
+	require.Equal(t, "table comment", *input.Description)
+	require.Equal(t, map[string]string{"classification": "parquet", "EXTERNAL": "TRUE"}, aws.StringValueMap(input.Parameters))
+	input = mock.createCalls[1].TableInput
+	require.Nil(t, input.Owner)
+	require.Nil(t, input.Description)
+	require.Equal(t, map[string]string{"EXTERNAL": "TRUE"}, aws.StringValueMap(input.Parameters))
+}
+
+func TestGlueMetaStore_AlterTable(t *testing.T) {
+	type args struct {
+		dbName string
+		table  model.TableInfo
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "shouldAlterTable",
+			args: args{
+				dbName: "pls",
+				table: model.TableInfo{
+					Name: "table",
+					Columns: []model.Column{
+						{
+							Name: "id",
+							Type: model.ColumnType{SqlType: model.BIGINT},
+						},
+						{
+							Name: "name",
+							Type: model.ColumnType{SqlType: model.VARCHAR, Length: 200},
+						},
+					},
+					Partitions: []model.Column{
+						{
+							Name: "partition",
+							Type: model.ColumnType{SqlType: model.BIGINT},
+						},
+					},
+					MetadataLocation: "s3a://bucket/table_v2",
+					Format:           model.PARQUET,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "shouldErrorWhenGlueError",
+			args: args{
+				dbName: "err",
+				table: model.TableInfo{
+					Name: "table",
+					Columns: []model.Column{
+						{
+							Name: "id",
+							Type: model.ColumnType{SqlType: model.BIGINT},
+						},
+					},
+					MetadataLocation: "s3://bucket/table",
+					Format:           model.PARQUET,
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &GlueMock{}
+			g := &GlueMetaStore{
+				glue: mock,
+			}
+			if err := g.AlterTable(tt.args.dbName, tt.args.table); (err != nil) != tt.wantErr {
+				t.Errorf("AlterTable() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			require.Len(t, mock.updateCalls, 1)
+			require.Equal(t, tt.args.dbName, *mock.updateCalls[0].DatabaseName)
+			require.Equal(t, tt.args.table.Name, *mock.updateCalls[0].TableInput.Name)
+			require.Equal(t, "s3://bucket/table_v2", *mock.updateCalls[0].TableInput.StorageDescriptor.Location)
+			require.Len(t, mock.updateCalls[0].TableInput.StorageDescriptor.Columns, len(tt.args.table.Columns))
+			require.Len(t, mock.updateCalls[0].TableInput.PartitionKeys, len(tt.args.table.Partitions))
+		})
+	}
+}
+
+func TestGlueMetaStore_AlterTableKeepsCurrentProperties(t *testing.T) {
+	mock := &GlueMock{
+		getTableOut: &glue.TableData{
+			Name:        aws.String("table"),
+			Description: aws.String("visits"),
+			Owner:       aws.String("data"),
+			Parameters:  aws.StringMap(map[string]string{"classification": "parquet", "lakeformation.owner": "data"}),
+			StorageDescriptor: &glue.StorageDescriptor{
+				Columns: []*glue.Column{
+					{Name: aws.String("id"), Type: aws.String("bigint"), Parameters: aws.StringMap(map[string]string{"iceberg.field.id": "1"})},
+				},
+			},
+		},
+	}
+	g := &GlueMetaStore{
+		glue: mock,
+	}
+	info := model.TableInfo{
+		Name: "table",
+		Columns: []model.Column{
+			{Name: "id", Type: model.ColumnType{SqlType: model.BIGINT}, Parameters: map[string]string{"pii": "false"}},
+			{Name: "name", Type: model.ColumnType{SqlType: model.STRING}},
+		},
+		MetadataLocation: "s3://bucket/table",
+		Format:           model.PARQUET,
+		Parameters:       map[string]string{"classification": "avro"},
+	}
+	require.NoError(t, g.AlterTable("pls", info))
+	require.Len(t, mock.updateCalls, 1)
+	input := mock.updateCalls[0].TableInput
+	require.Equal(t, map[string]string{"classification": "avro", "lakeformation.owner": "data", "EXTERNAL": "TRUE"}, aws.StringValueMap(input.Parameters))
+	require.Equal(t, "visits", *input.Description)
+	require.Equal(t, "data", *input.Owner)
+	require.Equal(t, map[string]string{"iceberg.field.id": "1", "pii": "false"}, aws.StringValueMap(input.StorageDescriptor.Columns[0].Parameters))
+	require.Empty(t, input.StorageDescriptor.Columns[1].Parameters)
+}
+
 func TestGlueMetaStore_DropTable(t *testing.T) {
 	type fields struct {
 		glue        glueiface.GlueAPI
@@ -509,7 +798,7 @@ func TestGlueMetaStore_DropTable(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			g := NewGlueMetaStore(tt.fields.glue, tt.fields.fileDeleter)
+			g := NewGlueMetaStore(tt.fields.glue, "", tt.fields.fileDeleter, nil)
 			if err := g.DropTable(tt.args.dbName, tt.args.tableName, tt.args.deleteData); (err != nil) != tt.wantErr {
 				t.Errorf("DropTable() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -533,3 +822,306 @@ func TestGlueMetaStore_DropTable(t *testing.T) {
 		})
 	}
 }
+
+func TestGlueMetaStore_GetPartitions(t *testing.T) {
+	g := &GlueMetaStore{
+		glue: &GlueMock{},
+	}
+	got, err := g.GetPartitions("pls", "table")
+	require.NoError(t, err)
+	require.Equal(t, []model.Partition{
+		{Values: []string{"1"}, Location: "s3://bucket/table/partition=1"},
+		{Values: []string{"2"}, Location: "s3://bucket/table/partition=2"},
+	}, got)
+
+	_, err = g.GetPartitions("err", "table")
+	require.Error(t, err)
+}
+
+func TestGlueMetaStore_AddPartitions(t *testing.T) {
+	mock := &GlueMock{}
+	g := &GlueMetaStore{
+		glue: mock,
+	}
+	partitions := make([]model.Partition, 150)
+	for i := range partitions {
+		partitions[i] = model.Partition{
+			Values:   []string{fmt.Sprintf("%d", i)},
+			Location: fmt.Sprintf("s3a://bucket/table/partition=%d", i),
+		}
+	}
+	require.NoError(t, g.AddPartitions("pls", "table", partitions))
+	require.Len(t, mock.createPartitionCalls, 2)
+	require.Len(t, mock.createPartitionCalls[0].PartitionInputList, 100)
+	require.Len(t, mock.createPartitionCalls[1].PartitionInputList, 50)
+	first := mock.createPartitionCalls[0].PartitionInputList[0]
+	require.Equal(t, []string{"0"}, aws.StringValueSlice(first.Values))
+	require.Equal(t, "s3://bucket/table/partition=0", *first.StorageDescriptor.Location)
+	require.Len(t, first.StorageDescriptor.Columns, 8)
+
+	require.Error(t, g.AddPartitions("pls", "table", []model.Partition{{Values: []string{"err"}}}))
+	require.Error(t, g.AddPartitions("pls", "nope", []model.Partition{{Values: []string{"1"}}}))
+}
+
+func TestGlueMetaStore_DropPartitions(t *testing.T) {
+	mock := &GlueMock{}
+	g := &GlueMetaStore{
+		glue: mock,
+	}
+	partitions := make([]model.Partition, 30)
+	for i := range partitions {
+		partitions[i] = model.Partition{Values: []string{fmt.Sprintf("%d", i)}}
+	}
+	require.NoError(t, g.DropPartitions("pls", "table", partitions))
+	require.Len(t, mock.deletePartitionCalls, 2)
+	require.Len(t, mock.deletePartitionCalls[0].PartitionsToDelete, 25)
+	require.Len(t, mock.deletePartitionCalls[1].PartitionsToDelete, 5)
+
+	require.Error(t, g.DropPartitions("err", "table", partitions))
+}
+
+func TestGlueMetaStore_GetDatabases(t *testing.T) {
+	g := &GlueMetaStore{
+		glue: &GlueMock{},
+	}
+	got, err := g.GetDatabases()
+	require.NoError(t, err)
+	require.Equal(t, []string{"default", "pls"}, got)
+}
+
+func TestGlueMetaStore_GetDatabaseInfo(t *testing.T) {
+	g := &GlueMetaStore{
+		glue: &GlueMock{},
+	}
+	got, err := g.GetDatabaseInfo("pls")
+	require.NoError(t, err)
+	require.Equal(t, model.Database{
+		Name:        "pls",
+		Description: "pls database",
+		Location:    "s3://bucket/pls",
+		Parameters:  map[string]string{"team": "data"},
+	}, got)
+
+	_, err = g.GetDatabaseInfo("nodb")
+	require.Error(t, err)
+}
+
+func TestGlueMetaStore_CreateDatabase(t *testing.T) {
+	mock := &GlueMock{}
+	g := &GlueMetaStore{
+		glue: mock,
+	}
+	require.NoError(t, g.CreateDatabase(model.Database{
+		Name:        "pls",
+		Description: "pls database",
+		Location:    "s3a://bucket/pls",
+		Parameters:  map[string]string{"team": "data"},
+	}))
+	require.NoError(t, g.CreateDatabase(model.Database{Name: "bare"}))
+	require.Error(t, g.CreateDatabase(model.Database{Name: "err"}))
+
+	require.Len(t, mock.createDbCalls, 3)
+	require.Equal(t, "pls", *mock.createDbCalls[0].DatabaseInput.Name)
+	require.Equal(t, "pls database", *mock.createDbCalls[0].DatabaseInput.Description)
+	require.Equal(t, "s3://bucket/pls", *mock.createDbCalls[0].DatabaseInput.LocationUri)
+	require.Equal(t, map[string]string{"team": "data"}, aws.StringValueMap(mock.createDbCalls[0].DatabaseInput.Parameters))
+	require.Nil(t, mock.createDbCalls[1].DatabaseInput.Description)
+	require.Nil(t, mock.createDbCalls[1].DatabaseInput.LocationUri)
+}
+
+func TestGlueMetaStore_DropDatabase(t *testing.T) {
+	mock := &GlueMock{}
+	g := &GlueMetaStore{
+		glue: mock,
+	}
+	require.Error(t, g.DropDatabase("pls", false))
+	require.Len(t, mock.deleteDbCalls, 0)
+
+	require.NoError(t, g.DropDatabase("pls", true))
+	require.Len(t, mock.deleteDbCalls, 1)
+	require.Equal(t, "pls", *mock.deleteDbCalls[0].Name)
+}
+
+func TestGlueMetaStore_CreateTableComplexTypes(t *testing.T) {
+	mock := &GlueMock{}
+	g := &GlueMetaStore{
+		glue: mock,
+	}
+	columnType, err := model.MapColumnType("struct<id:bigint,tags:array<varchar(20)>,amount:decimal(10,2)>")
+	require.NoError(t, err)
+	table := model.TableInfo{
+		Name:             "table",
+		Columns:          []model.Column{{Name: "payload", Type: columnType}},
+		MetadataLocation: "s3://bucket/table",
+		Format:           model.PARQUET,
+	}
+	require.NoError(t, g.CreateTable("pls", table))
+	require.Len(t, mock.createCalls, 1)
+	require.Equal(t, "struct<id:bigint,tags:array<varchar(20)>,amount:decimal(10,2)>", *mock.createCalls[0].TableInput.StorageDescriptor.Columns[0].Type)
+
+	table.Columns = []model.Column{{Name: "amount", Type: model.ColumnType{SqlType: model.DECIMAL, Precision: 50, Scale: 2}}}
+	require.Error(t, g.CreateTable("pls", table))
+	require.Len(t, mock.createCalls, 1)
+}
+
+func TestGlueMetaStore_CreateTableCsv(t *testing.T) {
+	mock := &GlueMock{}
+	g := &GlueMetaStore{
+		glue: mock,
+	}
+	table := model.TableInfo{
+		Name:             "table",
+		Columns:          []model.Column{{Name: "id", Type: model.ColumnType{SqlType: model.BIGINT}}},
+		MetadataLocation: "s3a://bucket/table",
+		Format:           model.CSV,
+		Parameters:       map[string]string{"skip.header.line.count": "1"},
+		SerdeParameters:  map[string]string{"separatorChar": ";"},
+	}
+	require.NoError(t, g.CreateTable("pls", table))
+	require.Len(t, mock.createCalls, 1)
+	input := mock.createCalls[0].TableInput
+	require.Equal(t, "org.apache.hadoop.mapred.TextInputFormat", *input.StorageDescriptor.InputFormat)
+	require.Equal(t, "org.apache.hadoop.hive.ql.io.HiveIgnoreKeyTextOutputFormat", *input.StorageDescriptor.OutputFormat)
+	require.Equal(t, "org.apache.hadoop.hive.serde2.OpenCSVSerde", *input.StorageDescriptor.SerdeInfo.SerializationLibrary)
+	require.Equal(t, map[string]string{"separatorChar": ";"}, aws.StringValueMap(input.StorageDescriptor.SerdeInfo.Parameters))
+	require.Equal(t, map[string]string{"skip.header.line.count": "1", "EXTERNAL": "TRUE"}, aws.StringValueMap(input.Parameters))
+	require.Equal(t, "s3://bucket/table", *input.StorageDescriptor.Location)
+}
+
+func TestGlueMetaStore_CreateTableTextFileSerdeParameters(t *testing.T) {
+	tests := []struct {
+		name            string
+		serdeParameters map[string]string
+		want            map[string]string
+	}{
+		{
+			name: "shouldDefaultHiveDelimiters",
+			want: map[string]string{"serialization.format": "1"},
+		},
+		{
+			name:            "shouldKeepSourceDelimiters",
+			serdeParameters: map[string]string{"field.delim": ",", "serialization.format": ","},
+			want:            map[string]string{"field.delim": ",", "serialization.format": ","},
+		},
+		{
+			name:            "shouldKeepSourceParameters",
+			serdeParameters: map[string]string{"serialization.format": "1"},
+			want:            map[string]string{"serialization.format": "1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &GlueMock{}
+			g := &GlueMetaStore{
+				glue: mock,
+			}
+			table := model.TableInfo{
+				Name:             "table",
+				Columns:          []model.Column{{Name: "id", Type: model.ColumnType{SqlType: model.BIGINT}}},
+				MetadataLocation: "s3://bucket/table",
+				Format:           model.TEXTFILE,
+				SerdeParameters:  tt.serdeParameters,
+			}
+			require.NoError(t, g.CreateTable("pls", table))
+			require.Len(t, mock.createCalls, 1)
+			input := mock.createCalls[0].TableInput
+			require.Equal(t, "org.apache.hadoop.hive.serde2.lazy.LazySimpleSerDe", *input.StorageDescriptor.SerdeInfo.SerializationLibrary)
+			require.Equal(t, tt.want, aws.StringValueMap(input.StorageDescriptor.SerdeInfo.Parameters))
+		})
+	}
+}
+
+func TestGlueMetaStore_CreateTableBucketing(t *testing.T) {
+	mock := &GlueMock{}
+	g := &GlueMetaStore{
+		glue: mock,
+	}
+	table := model.TableInfo{
+		Name:             "table",
+		Columns:          []model.Column{{Name: "id", Type: model.ColumnType{SqlType: model.BIGINT}}, {Name: "country", Type: model.ColumnType{SqlType: model.STRING}}},
+		MetadataLocation: "s3://bucket/table",
+		Format:           model.PARQUET,
+		Bucketing: &model.Bucketing{
+			Columns:     []string{"id"},
+			NumBuckets:  16,
+			SortColumns: []model.SortColumn{{Name: "id", Ascending: true}, {Name: "country", Ascending: false}},
+		},
+		Skewed: &model.SkewedInfo{
+			Columns:   []string{"id", "country"},
+			Values:    [][]string{{"1", "it"}, {"2", "us"}},
+			Locations: map[string]string{"1,it": "s3://bucket/table/skewed"},
+		},
+	}
+	require.NoError(t, g.CreateTable("pls", table))
+	require.Len(t, mock.createCalls, 1)
+	sd := mock.createCalls[0].TableInput.StorageDescriptor
+	require.Equal(t, int64(16), *sd.NumberOfBuckets)
+	require.Equal(t, []string{"1,it", "2,us"}, aws.StringValueSlice(sd.SkewedInfo.SkewedColumnValues))
+	require.Equal(t, int64(0), *sd.SortColumns[1].SortOrder)
+	require.Equal(t, table.Bucketing, mapBucketingGlue(sd))
+	require.Equal(t, table.Skewed, mapSkewedInfoGlue(sd.SkewedInfo))
+}
+
+func TestGlueMetaStore_AlterTableIceberg(t *testing.T) {
+	mock := &GlueMock{
+		getTableOut: &glue.TableData{
+			Name:      aws.String("table"),
+			TableType: aws.String("iceberg"),
+			StorageDescriptor: &glue.StorageDescriptor{
+				Location: aws.String("s3://bucket/table"),
+			},
+			Parameters: aws.StringMap(map[string]string{
+				"table_type":        "iceberg",
+				"metadata_location": "s3://bucket/table/metadata/00001-8051da97.metadata.json",
+			}),
+		},
+	}
+	g := &GlueMetaStore{
+		glue: mock,
+	}
+	info, err := g.GetTableInfo("pls", "table")
+	require.NoError(t, err)
+	require.Equal(t, model.TableFormat(model.ICEBERG), info.Format)
+	require.Equal(t, "s3://bucket/table/metadata/00001-8051da97.metadata.json", info.MetadataLocation)
+
+	info.Columns = []model.Column{{Name: "id", Type: model.ColumnType{SqlType: model.BIGINT}}}
+	info.MetadataLocation = "s3a://bucket/table/metadata/00002-1a2b3c4d.metadata.json"
+	require.NoError(t, g.AlterTable("pls", info))
+	require.Len(t, mock.updateCalls, 1)
+	input := mock.updateCalls[0].TableInput
+	require.Equal(t, "s3://bucket/table", *input.StorageDescriptor.Location)
+	require.Equal(t, "s3://bucket/table/metadata/00002-1a2b3c4d.metadata.json", *input.Parameters["metadata_location"])
+	require.Equal(t, "s3://bucket/table/metadata/00001-8051da97.metadata.json", *input.Parameters["previous_metadata_location"])
+}
+
+func TestGlueMetaStore_CatalogId(t *testing.T) {
+	mock := &GlueMock{}
+	g := NewGlueMetaStore(mock, "123456789012", &MockFileDeleter{}, nil)
+	_, err := g.GetDatabases()
+	require.NoError(t, err)
+	_, err = g.GetDatabaseInfo("pls")
+	require.NoError(t, err)
+	require.NoError(t, g.CreateDatabase(model.Database{Name: "pls"}))
+	require.NoError(t, g.DropDatabase("pls", true))
+	_, err = g.GetTables("pls")
+	require.NoError(t, err)
+	info, err := g.GetTableInfo("pls", "table")
+	require.NoError(t, err)
+	require.NoError(t, g.CreateTable("pls", info))
+	require.NoError(t, g.AlterTable("pls", info))
+	require.NoError(t, g.DropTable("pls", "table", false))
+	_, err = g.GetPartitions("pls", "table")
+	require.NoError(t, err)
+	partitions := []model.Partition{{Values: []string{"1"}, Location: "s3://bucket/table/partition=1"}}
+	require.NoError(t, g.AddPartitions("pls", "table", partitions))
+	require.NoError(t, g.DropPartitions("pls", "table", partitions))
+
+	catalogIds := mock.readCatalogIds
+	catalogIds = append(catalogIds, mock.createDbCalls[0].CatalogId, mock.deleteDbCalls[0].CatalogId)
+	catalogIds = append(catalogIds, mock.createCalls[0].CatalogId, mock.updateCalls[0].CatalogId, mock.deleteCalls[0].CatalogId)
+	catalogIds = append(catalogIds, mock.createPartitionCalls[0].CatalogId, mock.deletePartitionCalls[0].CatalogId)
+	require.Len(t, catalogIds, 17)
+	for _, catalogId := range catalogIds {
+		require.Equal(t, aws.String("123456789012"), catalogId)
+	}
+}
@@ -2,9 +2,11 @@ package metastore
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/akolb1/gometastore/hmsclient"
 	"github.com/akolb1/gometastore/hmsclient/thrift/gen-go/hive_metastore"
+	"github.com/hashicorp/go-multierror"
 	"github.com/sirupsen/logrus"
 	"github.com/the-Data-Appeal-Company/metaman/pkg/deleter"
 	"github.com/the-Data-Appeal-Company/metaman/pkg/model"
@@ -12,10 +14,19 @@ import (
 )
 
 type Hive interface {
+	GetAllDatabases() ([]string, error)
+	GetDatabase(dbName string) (*hmsclient.Database, error)
+	CreateDatabase(db *hmsclient.Database) error
+	DropDatabase(dbName string, deleteData bool, cascade bool) error
 	GetTable(dbName string, tableName string) (*hive_metastore.Table, error)
+	GetTableObjects(dbName string, tableNames []string) ([]*hive_metastore.Table, error)
 	GetAllTables(dbName string) ([]string, error)
 	CreateTable(table *hive_metastore.Table) error
+	AlterTable(dbName string, tableName string, table *hive_metastore.Table) error
 	DropTable(dbName string, tableName string, deleteData bool) error
+	GetPartitions(dbName string, tableName string, maxCount int) ([]*hive_metastore.Partition, error)
+	AddPartitions(newParts []*hive_metastore.Partition) error
+	DropPartition(dbName string, tableName string, values []string, dropData bool) (bool, error)
 	Close()
 }
 
@@ -40,14 +51,74 @@ func (h *HiveAlwaysRecreateFactory) GetHive() (Hive, error) {
 	return clientHive, nil
 }
 
+const (
+	hiveCommentParameter = "comment"
+	hiveDefaultOwner     = "metaman"
+	hiveNoBuckets        = -1
+
+	hiveGetTablesBatchSize = 100
+)
+
 type HiveMetaStore struct {
-	hiveFactory HiveFactory
-	fileDeleter deleter.FileDeleter
-	aux         AuxInfoRetriever
+	hiveFactory        HiveFactory
+	fileDeleter        deleter.FileDeleter
+	aux                AuxInfoRetriever
+	excludedParameters []string
 }
 
-func NewHiveMetaStore(hiveFactory HiveFactory, fileDeleter deleter.FileDeleter, aux AuxInfoRetriever) *HiveMetaStore {
-	return &HiveMetaStore{hiveFactory: hiveFactory, fileDeleter: fileDeleter, aux: aux}
+func NewHiveMetaStore(hiveFactory HiveFactory, fileDeleter deleter.FileDeleter, aux AuxInfoRetriever, excludedParameters []string) *HiveMetaStore {
+	return &HiveMetaStore{hiveFactory: hiveFactory, fileDeleter: fileDeleter, aux: aux, excludedParameters: excludedParameters}
+}
+
+func (h *HiveMetaStore) GetDatabases() ([]string, error) {
+	hive, err := h.hiveFactory.GetHive()
+	if err != nil {
+		return nil, err
+	}
+	defer hive.Close()
+	return hive.GetAllDatabases()
+}
+
+func (h *HiveMetaStore) GetDatabaseInfo(dbName string) (model.Database, error) {
+	hive, err := h.hiveFactory.GetHive()
+	if err != nil {
+		return model.Database{}, err
+	}
+	defer hive.Close()
+	db, err := hive.GetDatabase(dbName)
+	if err != nil {
+		return model.Database{}, err
+	}
+	return model.Database{
+		Name:        db.Name,
+		Description: db.Description,
+		Location:    db.Location,
+		Parameters:  db.Parameters,
+	}, nil
+}
+
+func (h *HiveMetaStore) CreateDatabase(database model.Database) error {
+	hive, err := h.hiveFactory.GetHive()
+	if err != nil {
+		return err
+	}
+	defer hive.Close()
+	return hive.CreateDatabase(&hmsclient.Database{
+		Name:        database.Name,
+		Description: database.Description,
+		Owner:       hiveDefaultOwner,
+		Location:    convertS3Format(HIVE, database.Location),
+		Parameters:  database.Parameters,
+	})
+}
+
+func (h *HiveMetaStore) DropDatabase(dbName string, cascade bool) error {
+	hive, err := h.hiveFactory.GetHive()
+	if err != nil {
+		return err
+	}
+	defer hive.Close()
+	return hive.DropDatabase(dbName, false, cascade)
 }
 
 func (h *HiveMetaStore) GetTables(dbName string) ([]string, error) {
@@ -65,51 +136,109 @@ func (h *HiveMetaStore) GetTableInfo(dbName, tableName string) (model.TableInfo,
 		return model.TableInfo{}, err
 	}
 	defer hive.Close()
+	return h.tableInfo(hive, dbName, tableName)
+}
+
+// tableInfo reads the table on a connection already borrowed by the caller.
+func (h *HiveMetaStore) tableInfo(hive Hive, dbName, tableName string) (model.TableInfo, error) {
 	table, err := hive.GetTable(dbName, tableName)
 	if err != nil {
 		return model.TableInfo{}, err
 	}
-	format := model.FromInputOutput(table.Sd.InputFormat)
-	location := table.Sd.Location
-	if format == model.ICEBERG {
-		location, err = h.aux.GetTableProperty(context.Background(), tableName, "metadata_location")
+	info := mapTableHive(table, h.excludedParameters)
+	if info.Format == model.ICEBERG {
+		info.MetadataLocation, err = h.aux.GetTableProperty(context.Background(), dbName, tableName, model.IcebergMetadataLocation)
 		if err != nil {
 			return model.TableInfo{}, err
 		}
 	}
-	return model.TableInfo{
-		Name:             table.GetTableName(),
-		Columns:          mapColumnsHive(table.Sd.Cols),
-		Partitions:       mapColumnsHive(table.PartitionKeys),
-		MetadataLocation: location,
-		Format:           format,
-	}, nil
+	return info, nil
+}
+
+// GetTablesInfo reads all the tables of the database in batches on a single connection,
+// the metadata location of iceberg tables is retrieved for the whole database at once.
+func (h *HiveMetaStore) GetTablesInfo(dbName string) ([]model.TableInfo, error) {
+	hive, err := h.hiveFactory.GetHive()
+	if err != nil {
+		return nil, err
+	}
+	defer hive.Close()
+	names, err := hive.GetAllTables(dbName)
+	if err != nil {
+		return nil, err
+	}
+	metadataLocations, err := h.aux.GetTablesProperty(context.Background(), dbName, model.IcebergMetadataLocation)
+	if err != nil {
+		return nil, err
+	}
+	var result error
+	infos := make([]model.TableInfo, 0, len(names))
+	for start := 0; start < len(names); start += hiveGetTablesBatchSize {
+		end := batchEnd(start, hiveGetTablesBatchSize, len(names))
+		tables, err := hive.GetTableObjects(dbName, names[start:end])
+		if err != nil {
+			return infos, multierror.Append(result, err)
+		}
+		for _, table := range tables {
+			info := mapTableHive(table, h.excludedParameters)
+			if info.Format == model.ICEBERG {
+				location, ok := metadataLocations[table.TableName]
+				if !ok {
+					result = multierror.Append(result, fmt.Errorf("table %s has no %s property", table.TableName, model.IcebergMetadataLocation))
+					continue
+				}
+				info.MetadataLocation = location
+			}
+			infos = append(infos, info)
+		}
+	}
+	return infos, result
 }
 
 func (h *HiveMetaStore) CreateTable(dbName string, table model.TableInfo) error {
 	if len(table.Columns) == 0 {
 		return fmt.Errorf("cannot Create table with 0 columns")
 	}
+	if err := validateColumnTypes(table); err != nil {
+		return err
+	}
 	hive, err := h.hiveFactory.GetHive()
 	if err != nil {
 		return err
 	}
 	defer hive.Close()
-	return hive.CreateTable(&hive_metastore.Table{
-		TableName: table.Name,
-		DbName:    dbName,
-		Owner:     "metaman",
-		Sd: &hive_metastore.StorageDescriptor{
-			Cols:         unmapColumnsHive(table.Columns),
-			Location:     getMetadataLocation(HIVE, table),
-			InputFormat:  table.Format.InputFormat(),
-			OutputFormat: table.Format.OutputFormat(),
-			SerdeInfo:    mapSerdeInfoHive(table.Format.SerDeInfo()),
-		},
-		PartitionKeys: unmapColumnsHive(table.Partitions),
-		Parameters:    table.Format.Parameters(convertS3Format(HIVE, table.MetadataLocation)),
-		TableType:     table.Format.TableType(),
-	})
+	return hive.CreateTable(buildTableHive(dbName, table))
+}
+
+func (h *HiveMetaStore) AlterTable(dbName string, table model.TableInfo) error {
+	if len(table.Columns) == 0 {
+		return fmt.Errorf("cannot alter table with 0 columns")
+	}
+	if err := validateColumnTypes(table); err != nil {
+		return err
+	}
+	hive, err := h.hiveFactory.GetHive()
+	if err != nil {
+		return err
+	}
+	defer hive.Close()
+	current, err := hive.GetTable(dbName, table.Name)
+	if err != nil {
+		return err
+	}
+	altered := buildTableHive(dbName, table)
+	if table.Owner == "" {
+		altered.Owner = current.Owner
+	}
+	altered.CreateTime = current.CreateTime
+	altered.Parameters = mergeParameters(current.Parameters, altered.Parameters)
+	if table.Format == model.ICEBERG {
+		previous := previousMetadataLocation(current.Parameters[model.IcebergMetadataLocation], altered.Parameters[model.IcebergMetadataLocation])
+		if previous != "" {
+			altered.Parameters[model.IcebergPreviousMetadataLocation] = previous
+		}
+	}
+	return hive.AlterTable(dbName, table.Name, altered)
 }
 
 func (h *HiveMetaStore) DropTable(dbName string, tableName string, deleteData bool) error {
@@ -118,9 +247,10 @@ func (h *HiveMetaStore) DropTable(dbName string, tableName string, deleteData bo
 		return err
 	}
 	defer hive.Close()
-	info, err := h.GetTableInfo(dbName, tableName)
+	info, err := h.tableInfo(hive, dbName, tableName)
 	if err != nil {
-		if _, ok := err.(*hive_metastore.NoSuchObjectException); ok {
+		var notFound *hive_metastore.NoSuchObjectException
+		if errors.As(err, &notFound) {
 			return nil
 		}
 		return err
@@ -130,8 +260,8 @@ func (h *HiveMetaStore) DropTable(dbName string, tableName string, deleteData bo
 		return err
 	}
 	if deleteData {
-		if isOnS3(info.MetadataLocation) {
-			bucket, path := getBucketPath(info.MetadataLocation)
+		if location := dataLocation(info); isOnS3(location) {
+			bucket, path := getBucketPath(location)
 			err := h.fileDeleter.Delete(context.Background(), bucket, path)
 			if err != nil {
 				logrus.Errorf("table dropped on hiveFactory but could not delete files if they are on s3")
@@ -142,30 +272,170 @@ func (h *HiveMetaStore) DropTable(dbName string, tableName string, deleteData bo
 	return nil
 }
 
+// KeptProperties tells that column parameters are not kept, hive columns having none.
+func (h *HiveMetaStore) KeptProperties() Properties {
+	return Properties{Description: true, TableParameters: true, SerdeParameters: true}
+}
+
+func (h *HiveMetaStore) GetPartitions(dbName, tableName string) ([]model.Partition, error) {
+	hive, err := h.hiveFactory.GetHive()
+	if err != nil {
+		return nil, err
+	}
+	defer hive.Close()
+	parts, err := hive.GetPartitions(dbName, tableName, -1)
+	if err != nil {
+		return nil, err
+	}
+	partitions := make([]model.Partition, len(parts))
+	for i, part := range parts {
+		partitions[i] = model.Partition{
+			Values:   part.Values,
+			Location: part.GetSd().GetLocation(),
+		}
+	}
+	return partitions, nil
+}
+
+func (h *HiveMetaStore) AddPartitions(dbName, tableName string, partitions []model.Partition) error {
+	if len(partitions) == 0 {
+		return nil
+	}
+	hive, err := h.hiveFactory.GetHive()
+	if err != nil {
+		return err
+	}
+	defer hive.Close()
+	table, err := hive.GetTable(dbName, tableName)
+	if err != nil {
+		return err
+	}
+	parts := make([]*hive_metastore.Partition, len(partitions))
+	for i, partition := range partitions {
+		sd := *table.Sd
+		sd.Location = convertS3Format(HIVE, partition.Location)
+		parts[i] = &hive_metastore.Partition{
+			Values:     partition.Values,
+			DbName:     dbName,
+			TableName:  tableName,
+			Sd:         &sd,
+			Parameters: map[string]string{},
+		}
+	}
+	return hive.AddPartitions(parts)
+}
+
+func (h *HiveMetaStore) DropPartitions(dbName, tableName string, partitions []model.Partition) error {
+	hive, err := h.hiveFactory.GetHive()
+	if err != nil {
+		return err
+	}
+	defer hive.Close()
+	var result error
+	for _, partition := range partitions {
+		_, err := hive.DropPartition(dbName, tableName, partition.Values, false)
+		if err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+	return result
+}
+
+func buildTableHive(dbName string, table model.TableInfo) *hive_metastore.Table {
+	owner := table.Owner
+	if owner == "" {
+		owner = hiveDefaultOwner
+	}
+	parameters := tableParameters(HIVE, table)
+	if table.Description != "" {
+		parameters[hiveCommentParameter] = table.Description
+	}
+	sd := &hive_metastore.StorageDescriptor{
+		Cols:         unmapColumnsHive(table.Columns),
+		Location:     getMetadataLocation(HIVE, table),
+		InputFormat:  table.InputFormat(),
+		OutputFormat: table.OutputFormat(),
+		SerdeInfo:    mapSerdeInfoHive(serDeInfo(HIVE, table)),
+		NumBuckets:   hiveNoBuckets,
+		SkewedInfo:   unmapSkewedInfoHive(table.Skewed),
+	}
+	if table.Bucketing != nil {
+		sd.NumBuckets = int32(table.Bucketing.NumBuckets)
+		sd.BucketCols = table.Bucketing.Columns
+		sd.SortCols = make([]*hive_metastore.Order, len(table.Bucketing.SortColumns))
+		for i, column := range table.Bucketing.SortColumns {
+			sd.SortCols[i] = &hive_metastore.Order{Col: column.Name, Order: sortOrder(column.Ascending)}
+		}
+	}
+	return &hive_metastore.Table{
+		TableName:     table.Name,
+		DbName:        dbName,
+		Owner:         owner,
+		Sd:            sd,
+		PartitionKeys: unmapColumnsHive(table.Partitions),
+		Parameters:    parameters,
+		TableType:     table.TableType(),
+	}
+}
+
 func unmapColumnsHive(columns []model.Column) []*hive_metastore.FieldSchema {
 	cols := make([]*hive_metastore.FieldSchema, len(columns))
 	for i, column := range columns {
 		cols[i] = &hive_metastore.FieldSchema{
-			Name: column.Name,
-			Type: mapHiveColumnType(model.UnmapColumnType(column.Type)),
+			Name:    column.Name,
+			Type:    HiveColumnType(model.UnmapColumnType(column.Type)),
+			Comment: column.Comment,
 		}
 	}
 	return cols
 }
 
-func mapHiveColumnType(columnType string) string {
+// HiveColumnType is the type hive columns are written with, strings being stored as varchar(1024).
+func HiveColumnType(columnType string) string {
 	if strings.ToLower(columnType) == "string" {
 		return "varchar(1024)"
 	}
 	return columnType
 }
 
+// mapTableHive maps a hive table, iceberg tables get their table root as location.
+func mapTableHive(table *hive_metastore.Table, excludedParameters []string) model.TableInfo {
+	serde := unmapSerdeInfoHive(table.Sd.SerdeInfo)
+	format := model.FromTable(table.Sd.InputFormat, table.Sd.Location, table.Parameters, serde)
+	parameters := filterParameters(table.Parameters, excludedParameters)
+	delete(parameters, hiveCommentParameter)
+	var storage *model.Storage
+	if format == model.UNKNOWN {
+		storage = &model.Storage{
+			InputFormat:      table.Sd.InputFormat,
+			OutputFormat:     table.Sd.OutputFormat,
+			SerializationLib: serde.SerializationLib,
+			TableType:        table.TableType,
+		}
+	}
+	return model.TableInfo{
+		Name:             table.GetTableName(),
+		Columns:          mapColumnsHive(table.Sd.Cols),
+		Partitions:       mapColumnsHive(table.PartitionKeys),
+		MetadataLocation: tableLocation(format, table.Sd.Location),
+		Format:           format,
+		Parameters:       parameters,
+		Description:      table.Parameters[hiveCommentParameter],
+		Owner:            table.Owner,
+		SerdeParameters:  serde.Parameters,
+		Storage:          storage,
+		Bucketing:        mapBucketingHive(table.Sd),
+		Skewed:           mapSkewedInfoHive(table.Sd.SkewedInfo),
+	}
+}
+
 func mapColumnsHive(cols []*hive_metastore.FieldSchema) []model.Column {
 	columns := make([]model.Column, len(cols))
 	for i, col := range cols {
 		columns[i] = model.Column{
-			Name: col.Name,
-			Type: model.MapColumnType(col.Type),
+			Name:    col.Name,
+			Type:    model.ReadColumnType(col.Type),
+			Comment: col.Comment,
 		}
 	}
 	return columns
@@ -180,3 +450,51 @@ func mapSerdeInfoHive(info *model.SerDeInfo) *hive_metastore.SerDeInfo {
 		Parameters:       info.Parameters,
 	}
 }
+
+func unmapSerdeInfoHive(info *hive_metastore.SerDeInfo) *model.SerDeInfo {
+	if info == nil {
+		return &model.SerDeInfo{}
+	}
+	serde := &model.SerDeInfo{SerializationLib: info.SerializationLib}
+	if len(info.Parameters) > 0 {
+		serde.Parameters = info.Parameters
+	}
+	return serde
+}
+
+func mapBucketingHive(sd *hive_metastore.StorageDescriptor) *model.Bucketing {
+	if len(sd.BucketCols) == 0 && len(sd.SortCols) == 0 {
+		return nil
+	}
+	bucketing := &model.Bucketing{
+		Columns:     sd.BucketCols,
+		NumBuckets:  int(sd.NumBuckets),
+		SortColumns: make([]model.SortColumn, len(sd.SortCols)),
+	}
+	for i, order := range sd.SortCols {
+		bucketing.SortColumns[i] = model.SortColumn{Name: order.Col, Ascending: order.Order == sortAscending}
+	}
+	return bucketing
+}
+
+func mapSkewedInfoHive(info *hive_metastore.SkewedInfo) *model.SkewedInfo {
+	if info == nil || len(info.SkewedColNames) == 0 {
+		return nil
+	}
+	return &model.SkewedInfo{
+		Columns:   info.SkewedColNames,
+		Values:    info.SkewedColValues,
+		Locations: info.SkewedColValueLocationMaps,
+	}
+}
+
+func unmapSkewedInfoHive(info *model.SkewedInfo) *hive_metastore.SkewedInfo {
+	if info == nil {
+		return nil
+	}
+	return &hive_metastore.SkewedInfo{
+		SkewedColNames:             info.Columns,
+		SkewedColValues:            info.Values,
+		SkewedColValueLocationMaps: info.Locations,
+	}
+}
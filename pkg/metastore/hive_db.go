@@ -0,0 +1,556 @@
+package metastore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/akolb1/gometastore/hmsclient/thrift/gen-go/hive_metastore"
+	"github.com/the-Data-Appeal-Company/metaman/pkg/model"
+	"strings"
+	"sync"
+)
+
+const (
+	// hiveDbCatalogColumnQuery tells whether databases belong to catalogs, from hive 3 on.
+	hiveDbCatalogColumnQuery = `SELECT COUNT(*) FROM information_schema.columns
+		WHERE table_schema = %s AND table_name = 'DBS' AND column_name = 'CTLG_NAME'`
+
+	hiveDbDatabasesQuery = `SELECT d."NAME" FROM "DBS" d WHERE %s ORDER BY d."NAME"`
+
+	hiveDbDatabaseQuery = `SELECT d."DB_ID", d."DESC", d."DB_LOCATION_URI" FROM "DBS" d WHERE %s`
+
+	hiveDbDatabaseParamsQuery = `SELECT "PARAM_KEY", "PARAM_VALUE" FROM "DATABASE_PARAMS" WHERE "DB_ID" = $1`
+
+	hiveDbTableNamesQuery = `SELECT t."TBL_NAME"
+		FROM "TBLS" t
+		JOIN "DBS" d ON t."DB_ID" = d."DB_ID"
+		WHERE %s
+		ORDER BY t."TBL_NAME"`
+
+	hiveDbTablesQuery = `SELECT t."TBL_ID", t."TBL_NAME", t."TBL_TYPE", t."OWNER",
+		s."INPUT_FORMAT", s."OUTPUT_FORMAT", s."LOCATION", s."NUM_BUCKETS", sd."SLIB"
+		FROM "TBLS" t
+		JOIN "DBS" d ON t."DB_ID" = d."DB_ID"
+		LEFT JOIN "SDS" s ON t."SD_ID" = s."SD_ID"
+		LEFT JOIN "SERDES" sd ON s."SERDE_ID" = sd."SERDE_ID"
+		WHERE %s
+		ORDER BY t."TBL_NAME"`
+
+	hiveDbColumnsQuery = `SELECT t."TBL_ID", c."COLUMN_NAME", c."TYPE_NAME", c."COMMENT"
+		FROM "TBLS" t
+		JOIN "DBS" d ON t."DB_ID" = d."DB_ID"
+		JOIN "SDS" s ON t."SD_ID" = s."SD_ID"
+		JOIN "COLUMNS_V2" c ON s."CD_ID" = c."CD_ID"
+		WHERE %s
+		ORDER BY t."TBL_ID", c."INTEGER_IDX"`
+
+	hiveDbPartitionKeysQuery = `SELECT t."TBL_ID", p."PKEY_NAME", p."PKEY_TYPE", p."PKEY_COMMENT"
+		FROM "TBLS" t
+		JOIN "DBS" d ON t."DB_ID" = d."DB_ID"
+		JOIN "PARTITION_KEYS" p ON t."TBL_ID" = p."TBL_ID"
+		WHERE %s
+		ORDER BY t."TBL_ID", p."INTEGER_IDX"`
+
+	hiveDbTableParamsQuery = `SELECT t."TBL_ID", p."PARAM_KEY", p."PARAM_VALUE"
+		FROM "TBLS" t
+		JOIN "DBS" d ON t."DB_ID" = d."DB_ID"
+		JOIN "TABLE_PARAMS" p ON t."TBL_ID" = p."TBL_ID"
+		WHERE %s`
+
+	hiveDbSerdeParamsQuery = `SELECT t."TBL_ID", p."PARAM_KEY", p."PARAM_VALUE"
+		FROM "TBLS" t
+		JOIN "DBS" d ON t."DB_ID" = d."DB_ID"
+		JOIN "SDS" s ON t."SD_ID" = s."SD_ID"
+		JOIN "SERDE_PARAMS" p ON s."SERDE_ID" = p."SERDE_ID"
+		WHERE %s`
+
+	hiveDbBucketColsQuery = `SELECT t."TBL_ID", b."BUCKET_COL_NAME"
+		FROM "TBLS" t
+		JOIN "DBS" d ON t."DB_ID" = d."DB_ID"
+		JOIN "SDS" s ON t."SD_ID" = s."SD_ID"
+		JOIN "BUCKETING_COLS" b ON s."SD_ID" = b."SD_ID"
+		WHERE %s
+		ORDER BY t."TBL_ID", b."INTEGER_IDX"`
+
+	hiveDbSortColsQuery = `SELECT t."TBL_ID", c."COLUMN_NAME", c."ORDER"
+		FROM "TBLS" t
+		JOIN "DBS" d ON t."DB_ID" = d."DB_ID"
+		JOIN "SDS" s ON t."SD_ID" = s."SD_ID"
+		JOIN "SORT_COLS" c ON s."SD_ID" = c."SD_ID"
+		WHERE %s
+		ORDER BY t."TBL_ID", c."INTEGER_IDX"`
+
+	hiveDbSkewedColNamesQuery = `SELECT t."TBL_ID", c."SKEWED_COL_NAME"
+		FROM "TBLS" t
+		JOIN "DBS" d ON t."DB_ID" = d."DB_ID"
+		JOIN "SDS" s ON t."SD_ID" = s."SD_ID"
+		JOIN "SKEWED_COL_NAMES" c ON s."SD_ID" = c."SD_ID"
+		WHERE %s
+		ORDER BY t."TBL_ID", c."INTEGER_IDX"`
+
+	hiveDbSkewedValuesQuery = `SELECT t."TBL_ID", v."STRING_LIST_ID_EID", l."STRING_LIST_VALUE"
+		FROM "TBLS" t
+		JOIN "DBS" d ON t."DB_ID" = d."DB_ID"
+		JOIN "SDS" s ON t."SD_ID" = s."SD_ID"
+		JOIN "SKEWED_VALUES" v ON s."SD_ID" = v."SD_ID_OID"
+		JOIN "SKEWED_STRING_LIST_VALUES" l ON v."STRING_LIST_ID_EID" = l."STRING_LIST_ID"
+		WHERE %s
+		ORDER BY t."TBL_ID", v."INTEGER_IDX", l."INTEGER_IDX"`
+
+	hiveDbSkewedLocationsQuery = `SELECT t."TBL_ID", m."STRING_LIST_ID_KID", l."STRING_LIST_VALUE", m."LOCATION"
+		FROM "TBLS" t
+		JOIN "DBS" d ON t."DB_ID" = d."DB_ID"
+		JOIN "SDS" s ON t."SD_ID" = s."SD_ID"
+		JOIN "SKEWED_COL_VALUE_LOC_MAP" m ON s."SD_ID" = m."SD_ID"
+		JOIN "SKEWED_STRING_LIST_VALUES" l ON m."STRING_LIST_ID_KID" = l."STRING_LIST_ID"
+		WHERE %s
+		ORDER BY t."TBL_ID", m."STRING_LIST_ID_KID", l."INTEGER_IDX"`
+
+	hiveDbPartitionsQuery = `SELECT p."PART_ID", s."LOCATION"
+		FROM "PARTITIONS" p
+		JOIN "TBLS" t ON p."TBL_ID" = t."TBL_ID"
+		JOIN "DBS" d ON t."DB_ID" = d."DB_ID"
+		LEFT JOIN "SDS" s ON p."SD_ID" = s."SD_ID"
+		WHERE %s
+		ORDER BY p."PART_ID"`
+
+	hiveDbPartitionValuesQuery = `SELECT v."PART_ID", v."PART_KEY_VAL"
+		FROM "PARTITION_KEY_VALS" v
+		JOIN "PARTITIONS" p ON v."PART_ID" = p."PART_ID"
+		JOIN "TBLS" t ON p."TBL_ID" = t."TBL_ID"
+		JOIN "DBS" d ON t."DB_ID" = d."DB_ID"
+		WHERE %s
+		ORDER BY v."PART_ID", v."INTEGER_IDX"`
+)
+
+var errHiveDbReadOnly = fmt.Errorf("%s metastore is read only", HIVE_DB)
+
+// DefaultHiveDbCatalog is the catalog of the databases created through the hive metastore.
+const DefaultHiveDbCatalog = "hive"
+
+// HiveDbMetaStore reads the hive metastore straight from its backing database,
+// loading all the tables of a database in a handful of queries. Queries are written
+// for postgres and rebound to the dialect of the database.
+// On hive 3 schemas, where databases belong to catalogs, only the databases of catalog are read.
+// It is read only, meant to be used as sync source.
+type HiveDbMetaStore struct {
+	db                 *sql.DB
+	dialect            SqlDialect
+	catalog            string
+	excludedParameters []string
+
+	catalogMutex  sync.Mutex
+	catalogColumn *bool
+}
+
+func NewHiveDbMetaStore(db *sql.DB, dialect SqlDialect, catalog string, excludedParameters []string) *HiveDbMetaStore {
+	if catalog == "" {
+		catalog = DefaultHiveDbCatalog
+	}
+	return &HiveDbMetaStore{db: db, dialect: dialect, catalog: catalog, excludedParameters: excludedParameters}
+}
+
+func (h *HiveDbMetaStore) GetDatabases() ([]string, error) {
+	filter, args, err := h.filter("", "")
+	if err != nil {
+		return nil, err
+	}
+	dbs := make([]string, 0)
+	err = h.query(fmt.Sprintf(hiveDbDatabasesQuery, filter), args, func(rows *sql.Rows) error {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		dbs = append(dbs, name)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dbs, nil
+}
+
+func (h *HiveDbMetaStore) GetDatabaseInfo(dbName string) (model.Database, error) {
+	filter, args, err := h.filter(dbName, "")
+	if err != nil {
+		return model.Database{}, err
+	}
+	var id int64
+	var description, location sql.NullString
+	row := h.db.QueryRowContext(context.Background(), h.dialect.rebind(fmt.Sprintf(hiveDbDatabaseQuery, filter)), args...)
+	if err := row.Scan(&id, &description, &location); err != nil {
+		if err == sql.ErrNoRows {
+			return model.Database{}, fmt.Errorf("database %s not found", dbName)
+		}
+		return model.Database{}, err
+	}
+	parameters := make(map[string]string)
+	err = h.query(hiveDbDatabaseParamsQuery, []interface{}{id}, func(rows *sql.Rows) error {
+		var key string
+		var value sql.NullString
+		if err := rows.Scan(&key, &value); err != nil {
+			return err
+		}
+		parameters[key] = value.String
+		return nil
+	})
+	if err != nil {
+		return model.Database{}, err
+	}
+	return model.Database{
+		Name:        dbName,
+		Description: description.String,
+		Location:    location.String,
+		Parameters:  parameters,
+	}, nil
+}
+
+func (h *HiveDbMetaStore) CreateDatabase(database model.Database) error {
+	return errHiveDbReadOnly
+}
+
+func (h *HiveDbMetaStore) DropDatabase(dbName string, cascade bool) error {
+	return errHiveDbReadOnly
+}
+
+func (h *HiveDbMetaStore) GetTables(dbName string) ([]string, error) {
+	filter, args, err := h.filter(dbName, "")
+	if err != nil {
+		return nil, err
+	}
+	tables := make([]string, 0)
+	err = h.query(fmt.Sprintf(hiveDbTableNamesQuery, filter), args, func(rows *sql.Rows) error {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		tables = append(tables, name)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tables, nil
+}
+
+func (h *HiveDbMetaStore) GetTableInfo(dbName, tableName string) (model.TableInfo, error) {
+	tables, err := h.loadTables(dbName, tableName)
+	if err != nil {
+		return model.TableInfo{}, err
+	}
+	if len(tables) == 0 {
+		return model.TableInfo{}, fmt.Errorf("table %s.%s not found", dbName, tableName)
+	}
+	return h.tableInfo(tables[0]), nil
+}
+
+// GetTablesInfo reads all the tables of the database.
+func (h *HiveDbMetaStore) GetTablesInfo(dbName string) ([]model.TableInfo, error) {
+	tables, err := h.loadTables(dbName, "")
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]model.TableInfo, 0, len(tables))
+	for _, table := range tables {
+		infos = append(infos, h.tableInfo(table))
+	}
+	return infos, nil
+}
+
+func (h *HiveDbMetaStore) CreateTable(dbName string, table model.TableInfo) error {
+	return errHiveDbReadOnly
+}
+
+func (h *HiveDbMetaStore) AlterTable(dbName string, table model.TableInfo) error {
+	return errHiveDbReadOnly
+}
+
+func (h *HiveDbMetaStore) DropTable(dbName string, tableName string, deleteData bool) error {
+	return errHiveDbReadOnly
+}
+
+// KeptProperties tells that column parameters are not kept, hive columns having none.
+func (h *HiveDbMetaStore) KeptProperties() Properties {
+	return Properties{Description: true, TableParameters: true, SerdeParameters: true}
+}
+
+func (h *HiveDbMetaStore) GetPartitions(dbName, tableName string) ([]model.Partition, error) {
+	filter, args, err := h.filter(dbName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	partitions := make([]model.Partition, 0)
+	index := make(map[int64]int)
+	err = h.query(fmt.Sprintf(hiveDbPartitionsQuery, filter), args, func(rows *sql.Rows) error {
+		var id int64
+		var location sql.NullString
+		if err := rows.Scan(&id, &location); err != nil {
+			return err
+		}
+		index[id] = len(partitions)
+		partitions = append(partitions, model.Partition{Values: make([]string, 0), Location: location.String})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = h.query(fmt.Sprintf(hiveDbPartitionValuesQuery, filter), args, func(rows *sql.Rows) error {
+		var id int64
+		var value sql.NullString
+		if err := rows.Scan(&id, &value); err != nil {
+			return err
+		}
+		if i, ok := index[id]; ok {
+			partitions[i].Values = append(partitions[i].Values, value.String)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return partitions, nil
+}
+
+func (h *HiveDbMetaStore) AddPartitions(dbName, tableName string, partitions []model.Partition) error {
+	return errHiveDbReadOnly
+}
+
+func (h *HiveDbMetaStore) DropPartitions(dbName, tableName string, partitions []model.Partition) error {
+	return errHiveDbReadOnly
+}
+
+func (h *HiveDbMetaStore) tableInfo(table *hive_metastore.Table) model.TableInfo {
+	info := mapTableHive(table, h.excludedParameters)
+	if info.Format == model.ICEBERG {
+		info.MetadataLocation = table.Parameters[model.IcebergMetadataLocation]
+	}
+	return info
+}
+
+// loadTables reads the tables of the database as the thrift api returns them,
+// all of them when tableName is empty.
+func (h *HiveDbMetaStore) loadTables(dbName, tableName string) ([]*hive_metastore.Table, error) {
+	filter, args, err := h.filter(dbName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	tables := make([]*hive_metastore.Table, 0)
+	byId := make(map[int64]*hive_metastore.Table)
+	err = h.query(fmt.Sprintf(hiveDbTablesQuery, filter), args, func(rows *sql.Rows) error {
+		var id int64
+		var name string
+		var tableType, owner, inputFormat, outputFormat, location, serializationLib sql.NullString
+		var numBuckets sql.NullInt64
+		if err := rows.Scan(&id, &name, &tableType, &owner, &inputFormat, &outputFormat, &location, &numBuckets, &serializationLib); err != nil {
+			return err
+		}
+		table := &hive_metastore.Table{
+			TableName:     name,
+			DbName:        dbName,
+			Owner:         owner.String,
+			TableType:     tableType.String,
+			Parameters:    make(map[string]string),
+			PartitionKeys: make([]*hive_metastore.FieldSchema, 0),
+			Sd: &hive_metastore.StorageDescriptor{
+				Cols:         make([]*hive_metastore.FieldSchema, 0),
+				Location:     location.String,
+				InputFormat:  inputFormat.String,
+				OutputFormat: outputFormat.String,
+				NumBuckets:   int32(numBuckets.Int64),
+				SerdeInfo:    &hive_metastore.SerDeInfo{SerializationLib: serializationLib.String},
+			},
+		}
+		tables = append(tables, table)
+		byId[id] = table
+		return nil
+	})
+	if err != nil || len(tables) == 0 {
+		return tables, err
+	}
+	// skewed value tuples are string lists spread over rows, gathered by list id
+	skewedValues := make(map[int64]int)
+	skewedLocations := make(map[int64]string)
+	loaders := []struct {
+		query string
+		load  func(rows *sql.Rows) (int64, func(table *hive_metastore.Table), error)
+	}{
+		{hiveDbColumnsQuery, func(rows *sql.Rows) (int64, func(table *hive_metastore.Table), error) {
+			id, column, err := scanFieldSchema(rows)
+			return id, func(table *hive_metastore.Table) { table.Sd.Cols = append(table.Sd.Cols, column) }, err
+		}},
+		{hiveDbPartitionKeysQuery, func(rows *sql.Rows) (int64, func(table *hive_metastore.Table), error) {
+			id, column, err := scanFieldSchema(rows)
+			return id, func(table *hive_metastore.Table) { table.PartitionKeys = append(table.PartitionKeys, column) }, err
+		}},
+		{hiveDbTableParamsQuery, func(rows *sql.Rows) (int64, func(table *hive_metastore.Table), error) {
+			id, key, value, err := scanParameter(rows)
+			return id, func(table *hive_metastore.Table) { table.Parameters[key] = value }, err
+		}},
+		{hiveDbSerdeParamsQuery, func(rows *sql.Rows) (int64, func(table *hive_metastore.Table), error) {
+			id, key, value, err := scanParameter(rows)
+			return id, func(table *hive_metastore.Table) {
+				if table.Sd.SerdeInfo.Parameters == nil {
+					table.Sd.SerdeInfo.Parameters = make(map[string]string)
+				}
+				table.Sd.SerdeInfo.Parameters[key] = value
+			}, err
+		}},
+		{hiveDbBucketColsQuery, func(rows *sql.Rows) (int64, func(table *hive_metastore.Table), error) {
+			var id int64
+			var column sql.NullString
+			err := rows.Scan(&id, &column)
+			return id, func(table *hive_metastore.Table) { table.Sd.BucketCols = append(table.Sd.BucketCols, column.String) }, err
+		}},
+		{hiveDbSortColsQuery, func(rows *sql.Rows) (int64, func(table *hive_metastore.Table), error) {
+			var id int64
+			var column sql.NullString
+			var order int32
+			err := rows.Scan(&id, &column, &order)
+			return id, func(table *hive_metastore.Table) {
+				table.Sd.SortCols = append(table.Sd.SortCols, &hive_metastore.Order{Col: column.String, Order: order})
+			}, err
+		}},
+		{hiveDbSkewedColNamesQuery, func(rows *sql.Rows) (int64, func(table *hive_metastore.Table), error) {
+			var id int64
+			var column sql.NullString
+			err := rows.Scan(&id, &column)
+			return id, func(table *hive_metastore.Table) {
+				info := skewedInfo(table)
+				info.SkewedColNames = append(info.SkewedColNames, column.String)
+			}, err
+		}},
+		{hiveDbSkewedValuesQuery, func(rows *sql.Rows) (int64, func(table *hive_metastore.Table), error) {
+			var id, listId int64
+			var value sql.NullString
+			err := rows.Scan(&id, &listId, &value)
+			return id, func(table *hive_metastore.Table) {
+				info := skewedInfo(table)
+				i, ok := skewedValues[listId]
+				if !ok {
+					i = len(info.SkewedColValues)
+					skewedValues[listId] = i
+					info.SkewedColValues = append(info.SkewedColValues, make([]string, 0))
+				}
+				info.SkewedColValues[i] = append(info.SkewedColValues[i], value.String)
+			}, err
+		}},
+		{hiveDbSkewedLocationsQuery, func(rows *sql.Rows) (int64, func(table *hive_metastore.Table), error) {
+			var id, listId int64
+			var value, location sql.NullString
+			err := rows.Scan(&id, &listId, &value, &location)
+			return id, func(table *hive_metastore.Table) {
+				info := skewedInfo(table)
+				key, ok := skewedLocations[listId]
+				if ok {
+					delete(info.SkewedColValueLocationMaps, key)
+					key += skewedValueSeparator + value.String
+				} else {
+					key = value.String
+				}
+				skewedLocations[listId] = key
+				info.SkewedColValueLocationMaps[key] = location.String
+			}, err
+		}},
+	}
+	for _, loader := range loaders {
+		load := loader.load
+		err := h.query(fmt.Sprintf(loader.query, filter), args, func(rows *sql.Rows) error {
+			id, apply, err := load(rows)
+			if err != nil {
+				return err
+			}
+			if table, ok := byId[id]; ok {
+				apply(table)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tables, nil
+}
+
+func (h *HiveDbMetaStore) query(query string, args []interface{}, scan func(rows *sql.Rows) error) error {
+	rows, err := h.db.QueryContext(context.Background(), h.dialect.rebind(query), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// filter restricts queries to the database when dbName is set, and to a single table when tableName is set too,
+// within the catalog of the metastore on hive 3 schemas.
+func (h *HiveDbMetaStore) filter(dbName, tableName string) (string, []interface{}, error) {
+	conditions := make([]string, 0, 3)
+	args := make([]interface{}, 0, 3)
+	if dbName != "" {
+		args = append(args, dbName)
+		conditions = append(conditions, fmt.Sprintf(`d."NAME" = $%d`, len(args)))
+	}
+	if tableName != "" {
+		args = append(args, tableName)
+		conditions = append(conditions, fmt.Sprintf(`t."TBL_NAME" = $%d`, len(args)))
+	}
+	catalogColumn, err := h.hasCatalogColumn()
+	if err != nil {
+		return "", nil, err
+	}
+	if catalogColumn {
+		args = append(args, h.catalog)
+		conditions = append(conditions, fmt.Sprintf(`d."CTLG_NAME" = $%d`, len(args)))
+	}
+	if len(conditions) == 0 {
+		return "1 = 1", args, nil
+	}
+	return strings.Join(conditions, " AND "), args, nil
+}
+
+// hasCatalogColumn tells whether the schema has database catalogs, checked once it succeeds.
+func (h *HiveDbMetaStore) hasCatalogColumn() (bool, error) {
+	h.catalogMutex.Lock()
+	defer h.catalogMutex.Unlock()
+	if h.catalogColumn != nil {
+		return *h.catalogColumn, nil
+	}
+	var count int
+	query := fmt.Sprintf(hiveDbCatalogColumnQuery, h.dialect.currentSchema())
+	if err := h.db.QueryRowContext(context.Background(), query).Scan(&count); err != nil {
+		return false, fmt.Errorf("could not check the %s schema for catalogs: %w", HIVE_DB, err)
+	}
+	catalogColumn := count > 0
+	h.catalogColumn = &catalogColumn
+	return catalogColumn, nil
+}
+
+// skewedInfo returns the skewed info of the table, created on first use.
+func skewedInfo(table *hive_metastore.Table) *hive_metastore.SkewedInfo {
+	if table.Sd.SkewedInfo == nil {
+		table.Sd.SkewedInfo = &hive_metastore.SkewedInfo{
+			SkewedColNames:             make([]string, 0),
+			SkewedColValues:            make([][]string, 0),
+			SkewedColValueLocationMaps: make(map[string]string),
+		}
+	}
+	return table.Sd.SkewedInfo
+}
+
+func scanFieldSchema(rows *sql.Rows) (int64, *hive_metastore.FieldSchema, error) {
+	var id int64
+	var name, columnType, comment sql.NullString
+	err := rows.Scan(&id, &name, &columnType, &comment)
+	return id, &hive_metastore.FieldSchema{Name: name.String, Type: columnType.String, Comment: comment.String}, err
+}
+
+func scanParameter(rows *sql.Rows) (int64, string, string, error) {
+	var id int64
+	var key, value sql.NullString
+	err := rows.Scan(&id, &key, &value)
+	return id, key.String, value.String, err
+}
@@ -0,0 +1,353 @@
+package metastore
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"github.com/the-Data-Appeal-Company/metaman/pkg/model"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// SqlResult is the result of the fake database for the queries containing match.
+type SqlResult struct {
+	match   string
+	columns []string
+	rows    [][]driver.Value
+}
+
+type SqlQuery struct {
+	query string
+	args  []driver.Value
+}
+
+// FakeSqlDb answers queries with the first result matching them and records the queries it receives.
+type FakeSqlDb struct {
+	results []SqlResult
+	queries []SqlQuery
+}
+
+var fakeSqlDbs = make(map[string]*FakeSqlDb)
+var fakeSqlDbCount int64
+
+func init() {
+	sql.Register("fake", fakeSqlDriver{})
+}
+
+func NewFakeSqlDb(t *testing.T, results ...SqlResult) (*sql.DB, *FakeSqlDb) {
+	fake := &FakeSqlDb{results: results}
+	name := fmt.Sprintf("db%d", atomic.AddInt64(&fakeSqlDbCount, 1))
+	fakeSqlDbs[name] = fake
+	db, err := sql.Open("fake", name)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+		delete(fakeSqlDbs, name)
+	})
+	return db, fake
+}
+
+type fakeSqlDriver struct{}
+
+func (d fakeSqlDriver) Open(name string) (driver.Conn, error) {
+	fake, ok := fakeSqlDbs[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown fake db %s", name)
+	}
+	return &fakeSqlConn{db: fake}, nil
+}
+
+type fakeSqlConn struct {
+	db *FakeSqlDb
+}
+
+func (c *fakeSqlConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("prepare not supported")
+}
+
+func (c *fakeSqlConn) Close() error {
+	return nil
+}
+
+func (c *fakeSqlConn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("transactions not supported")
+}
+
+func (c *fakeSqlConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	c.db.queries = append(c.db.queries, SqlQuery{query: query, args: values})
+	for _, result := range c.db.results {
+		if strings.Contains(query, result.match) {
+			return &fakeSqlRows{columns: result.columns, rows: result.rows}, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected query: %s", query)
+}
+
+type fakeSqlRows struct {
+	columns []string
+	rows    [][]driver.Value
+	next    int
+}
+
+func (r *fakeSqlRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeSqlRows) Close() error {
+	return nil
+}
+
+func (r *fakeSqlRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
+
+// hiveDbCatalogResult answers the check for database catalogs, present from hive 3 on.
+func hiveDbCatalogResult(catalogColumn bool) SqlResult {
+	count := int64(0)
+	if catalogColumn {
+		count = 1
+	}
+	return SqlResult{match: "information_schema", columns: []string{"COUNT"}, rows: [][]driver.Value{{count}}}
+}
+
+func hiveDbResults() []SqlResult {
+	return []SqlResult{
+		hiveDbCatalogResult(false),
+		{match: "SKEWED_COL_NAMES", columns: []string{"TBL_ID", "SKEWED_COL_NAME"}, rows: [][]driver.Value{
+			{int64(1), "id"},
+			{int64(1), "tags"},
+		}},
+		{match: "SKEWED_VALUES", columns: []string{"TBL_ID", "STRING_LIST_ID_EID", "STRING_LIST_VALUE"}, rows: [][]driver.Value{
+			{int64(1), int64(20), "1"},
+			{int64(1), int64(20), "a"},
+			{int64(1), int64(21), "2"},
+			{int64(1), int64(21), "b"},
+		}},
+		{match: "SKEWED_COL_VALUE_LOC_MAP", columns: []string{"TBL_ID", "STRING_LIST_ID_KID", "STRING_LIST_VALUE", "LOCATION"}, rows: [][]driver.Value{
+			{int64(1), int64(30), "1", "s3a://bucket/pls/events/id=1/tags=a"},
+			{int64(1), int64(30), "a", "s3a://bucket/pls/events/id=1/tags=a"},
+		}},
+		{match: "COLUMNS_V2", columns: []string{"TBL_ID", "COLUMN_NAME", "TYPE_NAME", "COMMENT"}, rows: [][]driver.Value{
+			{int64(1), "id", "bigint", "primary key"},
+			{int64(1), "tags", "array<string>", nil},
+			{int64(2), "id", "bigint", nil},
+			{int64(3), "id", "decimal(10,2", nil},
+			{int64(4), "id", "bigint", nil},
+		}},
+		{match: "PARTITION_KEY_VALS", columns: []string{"PART_ID", "PART_KEY_VAL"}, rows: [][]driver.Value{
+			{int64(10), "2023-01-01"},
+			{int64(10), "it"},
+			{int64(11), "2023-01-02"},
+			{int64(11), "fr"},
+		}},
+		{match: "PARTITION_KEYS", columns: []string{"TBL_ID", "PKEY_NAME", "PKEY_TYPE", "PKEY_COMMENT"}, rows: [][]driver.Value{
+			{int64(1), "dt", "string", nil},
+		}},
+		{match: "TABLE_PARAMS", columns: []string{"TBL_ID", "PARAM_KEY", "PARAM_VALUE"}, rows: [][]driver.Value{
+			{int64(1), "EXTERNAL", "TRUE"},
+			{int64(1), "comment", "events table"},
+			{int64(1), "transient_lastDdlTime", "1700000000"},
+			{int64(2), "table_type", "ICEBERG"},
+			{int64(2), "metadata_location", "s3a://bucket/pls/clicks/metadata/00002-aaaa.metadata.json"},
+		}},
+		{match: "SERDE_PARAMS", columns: []string{"TBL_ID", "PARAM_KEY", "PARAM_VALUE"}, rows: [][]driver.Value{
+			{int64(1), "serialization.format", "1"},
+		}},
+		{match: "BUCKETING_COLS", columns: []string{"TBL_ID", "BUCKET_COL_NAME"}, rows: [][]driver.Value{
+			{int64(1), "id"},
+		}},
+		{match: "SORT_COLS", columns: []string{"TBL_ID", "COLUMN_NAME", "ORDER"}, rows: [][]driver.Value{
+			{int64(1), "id", int64(0)},
+		}},
+		{match: "DATABASE_PARAMS", columns: []string{"PARAM_KEY", "PARAM_VALUE"}, rows: [][]driver.Value{
+			{"team", "data"},
+		}},
+		{match: "PARTITIONS", columns: []string{"PART_ID", "LOCATION"}, rows: [][]driver.Value{
+			{int64(10), "s3a://bucket/pls/events/dt=2023-01-01/country=it"},
+			{int64(11), nil},
+		}},
+		{match: "SLIB", columns: []string{"TBL_ID", "TBL_NAME", "TBL_TYPE", "OWNER", "INPUT_FORMAT", "OUTPUT_FORMAT", "LOCATION", "NUM_BUCKETS", "SLIB"}, rows: [][]driver.Value{
+			{int64(2), "clicks", "EXTERNAL_TABLE", "hive", "org.apache.hadoop.mapred.FileInputFormat", "org.apache.hadoop.mapred.FileOutputFormat", "s3a://bucket/pls/clicks", int64(-1), "org.apache.hadoop.hive.serde2.lazy.LazySimpleSerDe"},
+			{int64(1), "events", "EXTERNAL_TABLE", "etl", "org.apache.hadoop.hive.ql.io.parquet.MapredParquetInputFormat", "org.apache.hadoop.hive.ql.io.parquet.MapredParquetOutputFormat", "s3a://bucket/pls/events", int64(4), "org.apache.hadoop.hive.ql.io.parquet.serde.ParquetHiveSerDe"},
+			{int64(3), "invalid", "EXTERNAL_TABLE", nil, "org.apache.hadoop.hive.ql.io.parquet.MapredParquetInputFormat", "org.apache.hadoop.hive.ql.io.parquet.MapredParquetOutputFormat", "s3a://bucket/pls/invalid", int64(-1), "org.apache.hadoop.hive.ql.io.parquet.serde.ParquetHiveSerDe"},
+		}},
+		{match: "TBL_NAME", columns: []string{"TBL_NAME"}, rows: [][]driver.Value{
+			{"clicks"}, {"events"}, {"invalid"},
+		}},
+		{match: "DB_LOCATION_URI", columns: []string{"DB_ID", "DESC", "DB_LOCATION_URI"}, rows: [][]driver.Value{
+			{int64(7), "pls database", "s3a://bucket/pls"},
+		}},
+		{match: "DBS", columns: []string{"NAME"}, rows: [][]driver.Value{
+			{"default"}, {"pls"},
+		}},
+	}
+}
+
+func hiveDbEvents() model.TableInfo {
+	return model.TableInfo{
+		Name: "events",
+		Columns: []model.Column{
+			{Name: "id", Type: model.ColumnType{SqlType: model.BIGINT}, Comment: "primary key"},
+			{Name: "tags", Type: model.ColumnType{SqlType: model.ARRAY, Element: &model.ColumnType{SqlType: model.STRING}}},
+		},
+		Partitions:       []model.Column{{Name: "dt", Type: model.ColumnType{SqlType: model.STRING}}},
+		MetadataLocation: "s3a://bucket/pls/events",
+		Format:           model.PARQUET,
+		Parameters:       map[string]string{"EXTERNAL": "TRUE"},
+		Description:      "events table",
+		Owner:            "etl",
+		SerdeParameters:  map[string]string{"serialization.format": "1"},
+		Bucketing: &model.Bucketing{
+			Columns:     []string{"id"},
+			NumBuckets:  4,
+			SortColumns: []model.SortColumn{{Name: "id", Ascending: false}},
+		},
+		Skewed: &model.SkewedInfo{
+			Columns:   []string{"id", "tags"},
+			Values:    [][]string{{"1", "a"}, {"2", "b"}},
+			Locations: map[string]string{"1,a": "s3a://bucket/pls/events/id=1/tags=a"},
+		},
+	}
+}
+
+func TestHiveDbMetaStore_GetTablesInfo(t *testing.T) {
+	db, fake := NewFakeSqlDb(t, hiveDbResults()...)
+	h := NewHiveDbMetaStore(db, POSTGRES, "", []string{"transient_lastDdlTime"})
+
+	got, err := h.GetTablesInfo("pls")
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	require.Equal(t, "clicks", got[0].Name)
+	require.Equal(t, model.TableFormat(model.ICEBERG), got[0].Format)
+	require.Equal(t, "s3a://bucket/pls/clicks/metadata/00002-aaaa.metadata.json", got[0].MetadataLocation)
+	require.Equal(t, hiveDbEvents(), got[1])
+	require.Equal(t, []model.Column{{Name: "id", Type: model.ColumnType{SqlType: model.RAW, Definition: "decimal(10,2"}}}, got[2].Columns)
+
+	require.Len(t, fake.queries, 11)
+	require.Contains(t, fake.queries[0].query, "current_schema()")
+	for _, query := range fake.queries[1:] {
+		require.Equal(t, []driver.Value{"pls"}, query.args)
+		require.NotContains(t, query.query, "CTLG_NAME")
+	}
+}
+
+func TestHiveDbMetaStore_Catalog(t *testing.T) {
+	tests := []struct {
+		name        string
+		catalog     string
+		wantCatalog string
+	}{
+		{name: "shouldDefaultCatalog", wantCatalog: "hive"},
+		{name: "shouldConfiguredCatalog", catalog: "spark", wantCatalog: "spark"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := NewFakeSqlDb(t, append([]SqlResult{hiveDbCatalogResult(true)}, hiveDbResults()...)...)
+			h := NewHiveDbMetaStore(db, POSTGRES, tt.catalog, nil)
+
+			_, err := h.GetDatabases()
+			require.NoError(t, err)
+			require.Contains(t, fake.queries[1].query, `d."CTLG_NAME" = $1`)
+			require.Equal(t, []driver.Value{tt.wantCatalog}, fake.queries[1].args)
+
+			got, err := h.GetTablesInfo("pls")
+			require.NoError(t, err)
+			require.Len(t, got, 3)
+			require.Len(t, fake.queries, 12)
+			for _, query := range fake.queries[2:] {
+				require.Contains(t, query.query, `d."NAME" = $1 AND d."CTLG_NAME" = $2`)
+				require.Equal(t, []driver.Value{"pls", tt.wantCatalog}, query.args)
+			}
+		})
+	}
+}
+
+func TestHiveDbMetaStore_GetTablesInfoMysql(t *testing.T) {
+	db, fake := NewFakeSqlDb(t, hiveDbResults()...)
+	h := NewHiveDbMetaStore(db, MYSQL, "", []string{"transient_lastDdlTime"})
+
+	got, err := h.GetTablesInfo("pls")
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	require.Equal(t, hiveDbEvents(), got[1])
+	require.Contains(t, fake.queries[0].query, "DATABASE()")
+	for _, query := range fake.queries[1:] {
+		require.NotContains(t, query.query, `"`)
+		require.NotContains(t, query.query, "$1")
+		require.Contains(t, query.query, "d.`NAME` = ?")
+	}
+}
+
+func TestHiveDbMetaStore_GetTableInfo(t *testing.T) {
+	db, fake := NewFakeSqlDb(t, hiveDbResults()...)
+	h := NewHiveDbMetaStore(db, POSTGRES, "", []string{"transient_lastDdlTime"})
+
+	got, err := h.GetTableInfo("pls", "events")
+	require.NoError(t, err)
+	require.Equal(t, "clicks", got.Name)
+	require.Contains(t, fake.queries[1].query, `t."TBL_NAME" = $2`)
+	require.Equal(t, []driver.Value{"pls", "events"}, fake.queries[1].args)
+
+	db, _ = NewFakeSqlDb(t, hiveDbCatalogResult(false), SqlResult{match: "SLIB", columns: []string{"TBL_ID"}})
+	_, err = NewHiveDbMetaStore(db, POSTGRES, "", nil).GetTableInfo("pls", "missing")
+	require.EqualError(t, err, "table pls.missing not found")
+}
+
+func TestHiveDbMetaStore_GetDatabases(t *testing.T) {
+	db, _ := NewFakeSqlDb(t, hiveDbResults()...)
+	h := NewHiveDbMetaStore(db, POSTGRES, "", nil)
+
+	dbs, err := h.GetDatabases()
+	require.NoError(t, err)
+	require.Equal(t, []string{"default", "pls"}, dbs)
+
+	info, err := h.GetDatabaseInfo("pls")
+	require.NoError(t, err)
+	require.Equal(t, model.Database{
+		Name:        "pls",
+		Description: "pls database",
+		Location:    "s3a://bucket/pls",
+		Parameters:  map[string]string{"team": "data"},
+	}, info)
+
+	tables, err := h.GetTables("pls")
+	require.NoError(t, err)
+	require.Equal(t, []string{"clicks", "events", "invalid"}, tables)
+}
+
+func TestHiveDbMetaStore_GetPartitions(t *testing.T) {
+	db, _ := NewFakeSqlDb(t, hiveDbResults()...)
+	h := NewHiveDbMetaStore(db, POSTGRES, "", nil)
+
+	got, err := h.GetPartitions("pls", "events")
+	require.NoError(t, err)
+	require.Equal(t, []model.Partition{
+		{Values: []string{"2023-01-01", "it"}, Location: "s3a://bucket/pls/events/dt=2023-01-01/country=it"},
+		{Values: []string{"2023-01-02", "fr"}},
+	}, got)
+}
+
+func TestHiveDbMetaStore_ReadOnly(t *testing.T) {
+	db, _ := NewFakeSqlDb(t)
+	h := NewHiveDbMetaStore(db, POSTGRES, "", nil)
+	require.Error(t, h.CreateDatabase(model.Database{Name: "pls"}))
+	require.Error(t, h.DropDatabase("pls", true))
+	require.Error(t, h.CreateTable("pls", hiveDbEvents()))
+	require.Error(t, h.AlterTable("pls", hiveDbEvents()))
+	require.Error(t, h.DropTable("pls", "events", true))
+	require.Error(t, h.AddPartitions("pls", "events", []model.Partition{{Values: []string{"a"}}}))
+	require.Error(t, h.DropPartitions("pls", "events", []model.Partition{{Values: []string{"a"}}}))
+}
@@ -0,0 +1,235 @@
+package metastore
+
+import (
+	"fmt"
+	"github.com/akolb1/gometastore/hmsclient"
+	"github.com/akolb1/gometastore/hmsclient/thrift/gen-go/hive_metastore"
+	"github.com/hashicorp/go-multierror"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	defaultHiveFailureThreshold = 3
+	defaultHiveCooldown         = 30 * time.Second
+)
+
+var hiveConnections = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "metaman_hive_connections_total",
+	Help: "Connections opened to the hive metastore endpoints, by result.",
+}, []string{"endpoint", "result"})
+
+var hiveRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "metaman_hive_requests_total",
+	Help: "Requests to the hive metastore endpoints, pooled connections included, by result.",
+}, []string{"endpoint", "result"})
+
+// HiveEndpoint is one of the hive metastore instances, Address identifying it in logs and metrics.
+type HiveEndpoint struct {
+	Address string
+	Factory HiveFactory
+}
+
+// HiveFailoverFactory opens connections to the first available endpoint, in order or randomized.
+// An endpoint failing failureThreshold times in a row is skipped for cooldown, unless all of them are.
+type HiveFailoverFactory struct {
+	endpoints        []*hiveEndpointState
+	randomize        bool
+	failureThreshold int
+	cooldown         time.Duration
+	mu               sync.Mutex
+	now              func() time.Time
+}
+
+type hiveEndpointState struct {
+	HiveEndpoint
+	failures  int
+	openUntil time.Time
+}
+
+func NewHiveFailoverFactory(endpoints []HiveEndpoint, randomize bool, failureThreshold int, cooldown time.Duration) *HiveFailoverFactory {
+	if failureThreshold <= 0 {
+		failureThreshold = defaultHiveFailureThreshold
+	}
+	if cooldown <= 0 {
+		cooldown = defaultHiveCooldown
+	}
+	states := make([]*hiveEndpointState, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		states = append(states, &hiveEndpointState{HiveEndpoint: endpoint})
+	}
+	return &HiveFailoverFactory{endpoints: states, randomize: randomize, failureThreshold: failureThreshold, cooldown: cooldown, now: time.Now}
+}
+
+// GetHive connects to the first available endpoint. Failing to connect counts as an endpoint failure,
+// then the connection reports the outcome of its requests to the endpoint circuit for as long as
+// it is used, pooled or not, so that endpoints accepting connections but failing requests are skipped too.
+func (h *HiveFailoverFactory) GetHive() (Hive, error) {
+	var errs error
+	for _, endpoint := range h.candidates() {
+		hive, err := endpoint.Factory.GetHive()
+		if err == nil {
+			hiveConnections.WithLabelValues(endpoint.Address, "success").Inc()
+		} else {
+			hiveConnections.WithLabelValues(endpoint.Address, "failure").Inc()
+		}
+		if err != nil {
+			h.record(endpoint, err)
+			logrus.Warnf("hive metastore %s unavailable: %s", endpoint.Address, err.Error())
+			errs = multierror.Append(errs, fmt.Errorf("%s: %w", endpoint.Address, err))
+			continue
+		}
+		logrus.Infof("hive metastore %s serving connection", endpoint.Address)
+		return &endpointHive{hive: hive, endpoint: endpoint, factory: h}, nil
+	}
+	if errs == nil {
+		return nil, fmt.Errorf("no hive metastore endpoint configured")
+	}
+	return nil, errs
+}
+
+// candidates returns the endpoints to try, skipping the ones with an open circuit unless all of them are.
+func (h *HiveFailoverFactory) candidates() []*hiveEndpointState {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	endpoints := make([]*hiveEndpointState, len(h.endpoints))
+	copy(endpoints, h.endpoints)
+	if h.randomize {
+		rand.Shuffle(len(endpoints), func(i, j int) {
+			endpoints[i], endpoints[j] = endpoints[j], endpoints[i]
+		})
+	}
+	now := h.now()
+	closed := make([]*hiveEndpointState, 0, len(endpoints))
+	var open []*hiveEndpointState
+	for _, endpoint := range endpoints {
+		if now.Before(endpoint.openUntil) {
+			open = append(open, endpoint)
+		} else {
+			closed = append(closed, endpoint)
+		}
+	}
+	if len(closed) == 0 {
+		return open
+	}
+	return closed
+}
+
+// record updates the endpoint circuit with the outcome of a connection or of a request.
+func (h *HiveFailoverFactory) record(endpoint *hiveEndpointState, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err == nil {
+		if !endpoint.openUntil.IsZero() {
+			logrus.Infof("hive metastore %s recovered, no longer skipping it", endpoint.Address)
+		}
+		endpoint.failures = 0
+		endpoint.openUntil = time.Time{}
+		return
+	}
+	endpoint.failures++
+	if endpoint.failures >= h.failureThreshold {
+		logrus.Warnf("hive metastore %s failed %d times, skipping it for %s", endpoint.Address, endpoint.failures, h.cooldown)
+		endpoint.openUntil = h.now().Add(h.cooldown)
+		endpoint.failures = 0
+	}
+}
+
+// endpointHive is a connection to an endpoint, counting its requests and feeding
+// their transport errors to the endpoint circuit.
+type endpointHive struct {
+	hive     Hive
+	endpoint *hiveEndpointState
+	factory  *HiveFailoverFactory
+}
+
+// Endpoint is the address of the metastore the connection is open to.
+func (h *endpointHive) Endpoint() string {
+	return h.endpoint.Address
+}
+
+// check counts the request and records its outcome on the endpoint circuit, metastore exceptions
+// showing that the endpoint is reachable. Errors are returned labelled with the endpoint.
+func (h *endpointHive) check(err error) error {
+	switch {
+	case err == nil:
+		hiveRequests.WithLabelValues(h.endpoint.Address, "success").Inc()
+		h.factory.record(h.endpoint, nil)
+		return nil
+	case isTransportError(err):
+		hiveRequests.WithLabelValues(h.endpoint.Address, "transport_error").Inc()
+		logrus.Warnf("hive metastore %s transport error: %s", h.endpoint.Address, err.Error())
+		h.factory.record(h.endpoint, err)
+	default:
+		hiveRequests.WithLabelValues(h.endpoint.Address, "error").Inc()
+		h.factory.record(h.endpoint, nil)
+	}
+	return fmt.Errorf("hive metastore %s: %w", h.endpoint.Address, err)
+}
+
+func (h *endpointHive) GetAllDatabases() ([]string, error) {
+	dbs, err := h.hive.GetAllDatabases()
+	return dbs, h.check(err)
+}
+
+func (h *endpointHive) GetDatabase(dbName string) (*hmsclient.Database, error) {
+	db, err := h.hive.GetDatabase(dbName)
+	return db, h.check(err)
+}
+
+func (h *endpointHive) CreateDatabase(db *hmsclient.Database) error {
+	return h.check(h.hive.CreateDatabase(db))
+}
+
+func (h *endpointHive) DropDatabase(dbName string, deleteData bool, cascade bool) error {
+	return h.check(h.hive.DropDatabase(dbName, deleteData, cascade))
+}
+
+func (h *endpointHive) GetTable(dbName string, tableName string) (*hive_metastore.Table, error) {
+	table, err := h.hive.GetTable(dbName, tableName)
+	return table, h.check(err)
+}
+
+func (h *endpointHive) GetTableObjects(dbName string, tableNames []string) ([]*hive_metastore.Table, error) {
+	tables, err := h.hive.GetTableObjects(dbName, tableNames)
+	return tables, h.check(err)
+}
+
+func (h *endpointHive) GetAllTables(dbName string) ([]string, error) {
+	tables, err := h.hive.GetAllTables(dbName)
+	return tables, h.check(err)
+}
+
+func (h *endpointHive) CreateTable(table *hive_metastore.Table) error {
+	return h.check(h.hive.CreateTable(table))
+}
+
+func (h *endpointHive) AlterTable(dbName string, tableName string, table *hive_metastore.Table) error {
+	return h.check(h.hive.AlterTable(dbName, tableName, table))
+}
+
+func (h *endpointHive) DropTable(dbName string, tableName string, deleteData bool) error {
+	return h.check(h.hive.DropTable(dbName, tableName, deleteData))
+}
+
+func (h *endpointHive) GetPartitions(dbName string, tableName string, maxCount int) ([]*hive_metastore.Partition, error) {
+	partitions, err := h.hive.GetPartitions(dbName, tableName, maxCount)
+	return partitions, h.check(err)
+}
+
+func (h *endpointHive) AddPartitions(newParts []*hive_metastore.Partition) error {
+	return h.check(h.hive.AddPartitions(newParts))
+}
+
+func (h *endpointHive) DropPartition(dbName string, tableName string, values []string, dropData bool) (bool, error) {
+	dropped, err := h.hive.DropPartition(dbName, tableName, values, dropData)
+	return dropped, h.check(err)
+}
+
+func (h *endpointHive) Close() {
+	h.hive.Close()
+}
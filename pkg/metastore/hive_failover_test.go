@@ -0,0 +1,164 @@
+package metastore
+
+import (
+	"errors"
+	"github.com/akolb1/gometastore/hmsclient/thrift/gen-go/hive_metastore"
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type EndpointFactoryMock struct {
+	hive  Hive
+	err   error
+	calls int
+}
+
+func (f *EndpointFactoryMock) GetHive() (Hive, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.hive, nil
+}
+
+func TestHiveFailoverFactory_GetHive(t *testing.T) {
+	hive1, hive2 := &HiveMock{}, &HiveMock{}
+	tests := []struct {
+		name      string
+		factories []*EndpointFactoryMock
+		attempts  int
+		want      Hive
+		wantErr   bool
+		wantCalls []int
+	}{
+		{
+			name:      "shouldUseFirstEndpoint",
+			factories: []*EndpointFactoryMock{{hive: hive1}, {hive: hive2}},
+			attempts:  1,
+			want:      hive1,
+			wantCalls: []int{1, 0},
+		},
+		{
+			name:      "shouldFailoverToNextEndpoint",
+			factories: []*EndpointFactoryMock{{err: errors.New("connection refused")}, {hive: hive2}},
+			attempts:  1,
+			want:      hive2,
+			wantCalls: []int{1, 1},
+		},
+		{
+			name:      "shouldSkipEndpointWithOpenCircuit",
+			factories: []*EndpointFactoryMock{{err: errors.New("connection refused")}, {hive: hive2}},
+			attempts:  4,
+			want:      hive2,
+			wantCalls: []int{2, 4},
+		},
+		{
+			name:      "shouldTryAllEndpointsWithOpenCircuit",
+			factories: []*EndpointFactoryMock{{err: errors.New("connection refused")}, {err: errors.New("connection refused")}},
+			attempts:  3,
+			wantErr:   true,
+			wantCalls: []int{3, 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoints := make([]HiveEndpoint, 0, len(tt.factories))
+			for i, factory := range tt.factories {
+				endpoints = append(endpoints, HiveEndpoint{Address: tt.name + string(rune('a'+i)), Factory: factory})
+			}
+			h := NewHiveFailoverFactory(endpoints, false, 2, time.Minute)
+			var got Hive
+			var err error
+			for i := 0; i < tt.attempts; i++ {
+				got, err = h.GetHive()
+			}
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Same(t, tt.want, got.(*endpointHive).hive)
+			}
+			calls := make([]int, 0, len(tt.factories))
+			for _, factory := range tt.factories {
+				calls = append(calls, factory.calls)
+			}
+			require.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
+
+func TestHiveFailoverFactory_CircuitCloses(t *testing.T) {
+	failing := &EndpointFactoryMock{err: errors.New("connection refused")}
+	healthy := &EndpointFactoryMock{hive: &HiveMock{}}
+	h := NewHiveFailoverFactory([]HiveEndpoint{{Address: "hms-1:9083", Factory: failing}, {Address: "hms-2:9083", Factory: healthy}}, false, 1, time.Minute)
+	now := time.Now()
+	h.now = func() time.Time { return now }
+
+	_, err := h.GetHive()
+	require.NoError(t, err)
+	_, err = h.GetHive()
+	require.NoError(t, err)
+	require.Equal(t, 1, failing.calls)
+
+	now = now.Add(2 * time.Minute)
+	failing.err = nil
+	_, err = h.GetHive()
+	require.NoError(t, err)
+	require.Equal(t, 2, failing.calls)
+	require.Equal(t, 2, healthy.calls)
+	require.Equal(t, 1.0, testutil.ToFloat64(hiveConnections.WithLabelValues("hms-1:9083", "failure")))
+	require.Equal(t, 1.0, testutil.ToFloat64(hiveConnections.WithLabelValues("hms-1:9083", "success")))
+	require.Equal(t, 2.0, testutil.ToFloat64(hiveConnections.WithLabelValues("hms-2:9083", "success")))
+}
+
+func TestHiveFailoverFactory_NoEndpoints(t *testing.T) {
+	_, err := NewHiveFailoverFactory(nil, true, 0, 0).GetHive()
+	require.Error(t, err)
+}
+
+func TestHiveFailoverFactory_RequestErrorsOpenCircuit(t *testing.T) {
+	failing := &PooledHiveMock{tableErr: thrift.NewTTransportException(thrift.END_OF_FILE, "EOF")}
+	healthy := &EndpointFactoryMock{hive: &HiveMock{}}
+	h := NewHiveFailoverFactory([]HiveEndpoint{{Address: "hms-3:9083", Factory: &EndpointFactoryMock{hive: failing}}, {Address: "hms-4:9083", Factory: healthy}}, false, 2, time.Minute)
+	pool := NewHivePoolFactory(h, 1, 0, 0, 0)
+
+	for i := 0; i < 2; i++ {
+		hive, err := pool.GetHive()
+		require.NoError(t, err)
+		require.Equal(t, "hms-3:9083", hive.(*pooledHive).hive.(*endpointHive).Endpoint())
+		_, err = hive.GetTable("db", "table")
+		require.ErrorContains(t, err, "hms-3:9083")
+		require.True(t, isTransportError(err))
+		hive.Close()
+	}
+	require.Equal(t, 2.0, testutil.ToFloat64(hiveRequests.WithLabelValues("hms-3:9083", "transport_error")))
+
+	hive, err := pool.GetHive()
+	require.NoError(t, err)
+	require.Equal(t, "hms-4:9083", hive.(*pooledHive).hive.(*endpointHive).Endpoint())
+	hive.Close()
+}
+
+func TestHiveFailoverFactory_CountsPooledRequests(t *testing.T) {
+	hive := &PooledHiveMock{tableErr: &hive_metastore.NoSuchObjectException{Message: "table not found"}}
+	h := NewHiveFailoverFactory([]HiveEndpoint{{Address: "hms-5:9083", Factory: &EndpointFactoryMock{hive: hive}}}, false, 1, time.Minute)
+	pool := NewHivePoolFactory(h, 1, 0, 0, 0)
+
+	for i := 0; i < 3; i++ {
+		borrowed, err := pool.GetHive()
+		require.NoError(t, err)
+		_, err = borrowed.GetAllDatabases()
+		require.NoError(t, err)
+		_, err = borrowed.GetTable("db", "table")
+		require.ErrorContains(t, err, "hms-5:9083")
+		var notFound *hive_metastore.NoSuchObjectException
+		require.ErrorAs(t, err, &notFound)
+		borrowed.Close()
+	}
+	require.Equal(t, 1.0, testutil.ToFloat64(hiveConnections.WithLabelValues("hms-5:9083", "success")))
+	require.Equal(t, 3.0, testutil.ToFloat64(hiveRequests.WithLabelValues("hms-5:9083", "success")))
+	require.Equal(t, 3.0, testutil.ToFloat64(hiveRequests.WithLabelValues("hms-5:9083", "error")))
+}
@@ -0,0 +1,213 @@
+package metastore
+
+import (
+	"errors"
+	"fmt"
+	"github.com/akolb1/gometastore/hmsclient"
+	"github.com/akolb1/gometastore/hmsclient/thrift/gen-go/hive_metastore"
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/sirupsen/logrus"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultHiveBorrowTimeout is how long GetHive waits for a connection of a full pool by default.
+	DefaultHiveBorrowTimeout = time.Minute
+	// DefaultHiveHealthCheckIdle is how long a connection stays idle before being health checked on reuse by default.
+	DefaultHiveHealthCheckIdle = 30 * time.Second
+)
+
+// HivePoolFactory keeps hive connections opened by factory for reuse, with at most maxSize
+// of them open at once: GetHive waits up to borrowTimeout for one to be available. Connections
+// idle for more than idleTimeout are closed, the ones idle for more than healthCheckIdle are
+// health checked before being reused and the ones that hit a transport error are closed
+// instead of being returned to the pool.
+type HivePoolFactory struct {
+	factory         HiveFactory
+	idleTimeout     time.Duration
+	borrowTimeout   time.Duration
+	healthCheckIdle time.Duration
+	slots           chan struct{}
+	mu              sync.Mutex
+	idle            []idleHive
+}
+
+type idleHive struct {
+	hive  Hive
+	since time.Time
+}
+
+func NewHivePoolFactory(factory HiveFactory, maxSize int, idleTimeout, borrowTimeout, healthCheckIdle time.Duration) *HivePoolFactory {
+	if borrowTimeout <= 0 {
+		borrowTimeout = DefaultHiveBorrowTimeout
+	}
+	if healthCheckIdle <= 0 {
+		healthCheckIdle = DefaultHiveHealthCheckIdle
+	}
+	return &HivePoolFactory{
+		factory:         factory,
+		idleTimeout:     idleTimeout,
+		borrowTimeout:   borrowTimeout,
+		healthCheckIdle: healthCheckIdle,
+		slots:           make(chan struct{}, maxSize),
+	}
+}
+
+// GetHive borrows a connection, closing it returns it to the pool.
+func (p *HivePoolFactory) GetHive() (Hive, error) {
+	select {
+	case p.slots <- struct{}{}:
+	case <-time.After(p.borrowTimeout):
+		return nil, fmt.Errorf("no hive connection available in the pool after %s", p.borrowTimeout)
+	}
+	for {
+		idle, ok := p.popIdle()
+		if !ok {
+			break
+		}
+		if time.Since(idle.since) > p.healthCheckIdle {
+			if _, err := idle.hive.GetAllDatabases(); err != nil {
+				logrus.Warnf("discarding hive connection failing health check: %s", err.Error())
+				idle.hive.Close()
+				continue
+			}
+		}
+		return &pooledHive{hive: idle.hive, pool: p}, nil
+	}
+	hive, err := p.factory.GetHive()
+	if err != nil {
+		<-p.slots
+		return nil, err
+	}
+	return &pooledHive{hive: hive, pool: p}, nil
+}
+
+// Close closes the idle connections.
+func (p *HivePoolFactory) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, idle := range p.idle {
+		idle.hive.Close()
+	}
+	p.idle = nil
+}
+
+// popIdle returns the most recently used idle connection, closing the expired ones.
+func (p *HivePoolFactory) popIdle() (idleHive, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for len(p.idle) > 0 {
+		idle := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+		if p.idleTimeout > 0 && time.Since(idle.since) > p.idleTimeout {
+			idle.hive.Close()
+			continue
+		}
+		return idle, true
+	}
+	return idleHive{}, false
+}
+
+func (p *HivePoolFactory) release(hive Hive, broken bool) {
+	if broken {
+		hive.Close()
+	} else {
+		p.mu.Lock()
+		p.idle = append(p.idle, idleHive{hive: hive, since: time.Now()})
+		p.mu.Unlock()
+	}
+	<-p.slots
+}
+
+// isTransportError tells whether err leaves the connection unusable,
+// as opposed to the exceptions returned by the metastore itself.
+func isTransportError(err error) bool {
+	var transportErr thrift.TTransportException
+	var protocolErr thrift.TProtocolException
+	var netErr net.Error
+	return errors.As(err, &transportErr) || errors.As(err, &protocolErr) || errors.As(err, &netErr)
+}
+
+// pooledHive is a borrowed connection, returned to the pool on Close.
+type pooledHive struct {
+	hive     Hive
+	pool     *HivePoolFactory
+	broken   bool
+	released bool
+}
+
+func (h *pooledHive) check(err error) error {
+	if err != nil && isTransportError(err) {
+		h.broken = true
+	}
+	return err
+}
+
+func (h *pooledHive) GetAllDatabases() ([]string, error) {
+	dbs, err := h.hive.GetAllDatabases()
+	return dbs, h.check(err)
+}
+
+func (h *pooledHive) GetDatabase(dbName string) (*hmsclient.Database, error) {
+	db, err := h.hive.GetDatabase(dbName)
+	return db, h.check(err)
+}
+
+func (h *pooledHive) CreateDatabase(db *hmsclient.Database) error {
+	return h.check(h.hive.CreateDatabase(db))
+}
+
+func (h *pooledHive) DropDatabase(dbName string, deleteData bool, cascade bool) error {
+	return h.check(h.hive.DropDatabase(dbName, deleteData, cascade))
+}
+
+func (h *pooledHive) GetTable(dbName string, tableName string) (*hive_metastore.Table, error) {
+	table, err := h.hive.GetTable(dbName, tableName)
+	return table, h.check(err)
+}
+
+func (h *pooledHive) GetTableObjects(dbName string, tableNames []string) ([]*hive_metastore.Table, error) {
+	tables, err := h.hive.GetTableObjects(dbName, tableNames)
+	return tables, h.check(err)
+}
+
+func (h *pooledHive) GetAllTables(dbName string) ([]string, error) {
+	tables, err := h.hive.GetAllTables(dbName)
+	return tables, h.check(err)
+}
+
+func (h *pooledHive) CreateTable(table *hive_metastore.Table) error {
+	return h.check(h.hive.CreateTable(table))
+}
+
+func (h *pooledHive) AlterTable(dbName string, tableName string, table *hive_metastore.Table) error {
+	return h.check(h.hive.AlterTable(dbName, tableName, table))
+}
+
+func (h *pooledHive) DropTable(dbName string, tableName string, deleteData bool) error {
+	return h.check(h.hive.DropTable(dbName, tableName, deleteData))
+}
+
+func (h *pooledHive) GetPartitions(dbName string, tableName string, maxCount int) ([]*hive_metastore.Partition, error) {
+	partitions, err := h.hive.GetPartitions(dbName, tableName, maxCount)
+	return partitions, h.check(err)
+}
+
+func (h *pooledHive) AddPartitions(newParts []*hive_metastore.Partition) error {
+	return h.check(h.hive.AddPartitions(newParts))
+}
+
+func (h *pooledHive) DropPartition(dbName string, tableName string, values []string, dropData bool) (bool, error) {
+	dropped, err := h.hive.DropPartition(dbName, tableName, values, dropData)
+	return dropped, h.check(err)
+}
+
+func (h *pooledHive) Close() {
+	if h.released {
+		return
+	}
+	h.released = true
+	h.pool.release(h.hive, h.broken)
+}
@@ -0,0 +1,213 @@
+package metastore
+
+import (
+	"errors"
+	"github.com/akolb1/gometastore/hmsclient/thrift/gen-go/hive_metastore"
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type PooledHiveMock struct {
+	HiveMock
+	healthErr error
+	tableErr  error
+	closed    bool
+}
+
+func (h *PooledHiveMock) GetAllDatabases() ([]string, error) {
+	return nil, h.healthErr
+}
+
+func (h *PooledHiveMock) GetTable(dbName string, tableName string) (*hive_metastore.Table, error) {
+	return nil, h.tableErr
+}
+
+func (h *PooledHiveMock) Close() {
+	h.closed = true
+}
+
+type CountingHiveFactoryMock struct {
+	opened []*PooledHiveMock
+	err    error
+}
+
+func (f *CountingHiveFactoryMock) GetHive() (Hive, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	hive := &PooledHiveMock{}
+	f.opened = append(f.opened, hive)
+	return hive, nil
+}
+
+func TestHivePoolFactory_GetHive(t *testing.T) {
+	tests := []struct {
+		name            string
+		idleTimeout     time.Duration
+		healthCheckIdle time.Duration
+		use             func(t *testing.T, pool *HivePoolFactory)
+		wantOpened      int
+		wantClosed      []bool
+	}{
+		{
+			name: "shouldReuseReturnedConnection",
+			use: func(t *testing.T, pool *HivePoolFactory) {
+				for i := 0; i < 3; i++ {
+					hive, err := pool.GetHive()
+					require.NoError(t, err)
+					hive.Close()
+				}
+			},
+			wantOpened: 1,
+			wantClosed: []bool{false},
+		},
+		{
+			name: "shouldOpenConnectionPerConcurrentBorrow",
+			use: func(t *testing.T, pool *HivePoolFactory) {
+				first, err := pool.GetHive()
+				require.NoError(t, err)
+				second, err := pool.GetHive()
+				require.NoError(t, err)
+				first.Close()
+				second.Close()
+			},
+			wantOpened: 2,
+			wantClosed: []bool{false, false},
+		},
+		{
+			name:        "shouldCloseExpiredIdleConnection",
+			idleTimeout: time.Nanosecond,
+			use: func(t *testing.T, pool *HivePoolFactory) {
+				hive, err := pool.GetHive()
+				require.NoError(t, err)
+				hive.Close()
+				time.Sleep(time.Millisecond)
+				hive, err = pool.GetHive()
+				require.NoError(t, err)
+				hive.Close()
+			},
+			wantOpened: 2,
+			wantClosed: []bool{true, false},
+		},
+		{
+			name:            "shouldDiscardConnectionFailingHealthCheck",
+			healthCheckIdle: time.Nanosecond,
+			use: func(t *testing.T, pool *HivePoolFactory) {
+				hive, err := pool.GetHive()
+				require.NoError(t, err)
+				hive.Close()
+				time.Sleep(time.Millisecond)
+				pool.factory.(*CountingHiveFactoryMock).opened[0].healthErr = errors.New("connection reset")
+				hive, err = pool.GetHive()
+				require.NoError(t, err)
+				hive.Close()
+			},
+			wantOpened: 2,
+			wantClosed: []bool{true, false},
+		},
+		{
+			name: "shouldSkipHealthCheckOfRecentConnection",
+			use: func(t *testing.T, pool *HivePoolFactory) {
+				hive, err := pool.GetHive()
+				require.NoError(t, err)
+				hive.Close()
+				pool.factory.(*CountingHiveFactoryMock).opened[0].healthErr = errors.New("connection reset")
+				hive, err = pool.GetHive()
+				require.NoError(t, err)
+				hive.Close()
+			},
+			wantOpened: 1,
+			wantClosed: []bool{false},
+		},
+		{
+			name: "shouldEvictConnectionOnTransportError",
+			use: func(t *testing.T, pool *HivePoolFactory) {
+				hive, err := pool.GetHive()
+				require.NoError(t, err)
+				pool.factory.(*CountingHiveFactoryMock).opened[0].tableErr = thrift.NewTTransportException(thrift.END_OF_FILE, "EOF")
+				_, err = hive.GetTable("db", "table")
+				require.Error(t, err)
+				hive.Close()
+				hive, err = pool.GetHive()
+				require.NoError(t, err)
+				hive.Close()
+			},
+			wantOpened: 2,
+			wantClosed: []bool{true, false},
+		},
+		{
+			name: "shouldKeepConnectionOnMetastoreError",
+			use: func(t *testing.T, pool *HivePoolFactory) {
+				hive, err := pool.GetHive()
+				require.NoError(t, err)
+				pool.factory.(*CountingHiveFactoryMock).opened[0].tableErr = &hive_metastore.NoSuchObjectException{Message: "table not found"}
+				_, err = hive.GetTable("db", "table")
+				require.Error(t, err)
+				hive.Close()
+				hive.Close()
+				hive, err = pool.GetHive()
+				require.NoError(t, err)
+				hive.Close()
+			},
+			wantOpened: 1,
+			wantClosed: []bool{false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory := &CountingHiveFactoryMock{}
+			pool := NewHivePoolFactory(factory, 2, tt.idleTimeout, 0, tt.healthCheckIdle)
+			tt.use(t, pool)
+			require.Len(t, factory.opened, tt.wantOpened)
+			closed := make([]bool, 0, len(factory.opened))
+			for _, hive := range factory.opened {
+				closed = append(closed, hive.closed)
+			}
+			require.Equal(t, tt.wantClosed, closed)
+		})
+	}
+}
+
+func TestHivePoolFactory_GetHiveBlocksWhenFull(t *testing.T) {
+	pool := NewHivePoolFactory(&CountingHiveFactoryMock{}, 1, 0, 0, 0)
+	first, err := pool.GetHive()
+	require.NoError(t, err)
+
+	borrowed := make(chan Hive)
+	go func() {
+		hive, _ := pool.GetHive()
+		borrowed <- hive
+	}()
+	select {
+	case <-borrowed:
+		t.Fatal("borrowed a connection beyond the pool size")
+	case <-time.After(50 * time.Millisecond):
+	}
+	first.Close()
+	second := <-borrowed
+	require.NotNil(t, second)
+	second.Close()
+}
+
+func TestHivePoolFactory_GetHiveBorrowTimeout(t *testing.T) {
+	pool := NewHivePoolFactory(&CountingHiveFactoryMock{}, 1, 0, 10*time.Millisecond, 0)
+	first, err := pool.GetHive()
+	require.NoError(t, err)
+	_, err = pool.GetHive()
+	require.Error(t, err)
+	first.Close()
+	second, err := pool.GetHive()
+	require.NoError(t, err)
+	second.Close()
+}
+
+func TestHivePoolFactory_GetHiveError(t *testing.T) {
+	pool := NewHivePoolFactory(&CountingHiveFactoryMock{err: errors.New("connection refused")}, 1, 0, 0, 0)
+	_, err := pool.GetHive()
+	require.Error(t, err)
+	// the slot of the failed open is released
+	_, err = pool.GetHive()
+	require.Error(t, err)
+}
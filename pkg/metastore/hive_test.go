@@ -3,6 +3,7 @@ package metastore
 import (
 	"context"
 	"fmt"
+	"github.com/akolb1/gometastore/hmsclient"
 	"github.com/akolb1/gometastore/hmsclient/thrift/gen-go/hive_metastore"
 	"github.com/stretchr/testify/require"
 	"github.com/the-Data-Appeal-Company/metaman/pkg/deleter"
@@ -12,12 +13,21 @@ import (
 )
 
 type AuxMock struct {
+	getTablesPropertyCalls []string
 }
 
-func (a *AuxMock) GetTableProperty(_ context.Context, table, _ string) (string, error) {
+func (a *AuxMock) GetTableProperty(_ context.Context, _, table, _ string) (string, error) {
 	return fmt.Sprintf("s3://bucket/%s/metadata/hcidhihcid.json", table), nil
 }
 
+func (a *AuxMock) GetTablesProperty(_ context.Context, dbName, _ string) (map[string]string, error) {
+	a.getTablesPropertyCalls = append(a.getTablesPropertyCalls, dbName)
+	if dbName != "pls" {
+		return nil, fmt.Errorf("could not read properties")
+	}
+	return map[string]string{"table": "s3://bucket/table/metadata/00002-aaaa.metadata.json"}, nil
+}
+
 type MockFileDeleter struct {
 	paths map[string][]string
 	err   error
@@ -51,9 +61,51 @@ func (h *HiveFactoryMock) GetHive() (Hive, error) {
 }
 
 type HiveMock struct {
-	getTableInfoError error
-	createCalls       []*hive_metastore.Table
-	dropCalls         []DropCall
+	getTableInfoError  error
+	getTableOut        *hive_metastore.Table
+	createCalls        []*hive_metastore.Table
+	alterCalls         []*hive_metastore.Table
+	dropCalls          []DropCall
+	addPartitionCalls  []*hive_metastore.Partition
+	dropPartitionCalls [][]string
+	createDbCalls      []*hmsclient.Database
+	dropDbCalls        []DropCall
+}
+
+func (h *HiveMock) GetAllDatabases() ([]string, error) {
+	return []string{"default", "pls"}, nil
+}
+
+func (h *HiveMock) GetDatabase(dbName string) (*hmsclient.Database, error) {
+	if dbName != "pls" {
+		return nil, hive_metastore.NewNoSuchObjectException()
+	}
+	return &hmsclient.Database{
+		Name:        "pls",
+		Description: "pls database",
+		Owner:       "sap",
+		Location:    "s3a://bucket/pls",
+		Parameters:  map[string]string{"team": "data"},
+	}, nil
+}
+
+func (h *HiveMock) CreateDatabase(db *hmsclient.Database) error {
+	h.createDbCalls = append(h.createDbCalls, db)
+	if db.Name == "err" {
+		return fmt.Errorf("could not create database")
+	}
+	return nil
+}
+
+func (h *HiveMock) DropDatabase(dbName string, deleteData bool, cascade bool) error {
+	h.dropDbCalls = append(h.dropDbCalls, DropCall{
+		dbName:     dbName,
+		deleteData: deleteData,
+	})
+	if !cascade {
+		return fmt.Errorf("database %s is not empty", dbName)
+	}
+	return nil
 }
 
 func (h *HiveMock) GetAllTables(dbName string) ([]string, error) {
@@ -74,6 +126,14 @@ func (h *HiveMock) CreateTable(table *hive_metastore.Table) error {
 	return nil
 }
 
+func (h *HiveMock) AlterTable(dbName string, tableName string, table *hive_metastore.Table) error {
+	h.alterCalls = append(h.alterCalls, table)
+	if dbName != "pls" || tableName != table.TableName {
+		return fmt.Errorf("could not alter table")
+	}
+	return nil
+}
+
 func (h *HiveMock) DropTable(dbName string, tableName string, deleteData bool) error {
 	h.dropCalls = append(h.dropCalls, DropCall{
 		dbName:     dbName,
@@ -93,6 +153,9 @@ func (h *HiveMock) GetTable(dbName string, tableName string) (*hive_metastore.Ta
 	if h.getTableInfoError != nil {
 		return nil, h.getTableInfoError
 	}
+	if h.getTableOut != nil {
+		return h.getTableOut, nil
+	}
 	return &hive_metastore.Table{
 		TableName:  "table",
 		DbName:     "pls",
@@ -101,8 +164,9 @@ func (h *HiveMock) GetTable(dbName string, tableName string) (*hive_metastore.Ta
 		Sd: &hive_metastore.StorageDescriptor{
 			Cols: []*hive_metastore.FieldSchema{
 				{
-					Name: "id",
-					Type: "bigint",
+					Name:    "id",
+					Type:    "bigint",
+					Comment: "primary key",
 				},
 				{
 					Name: "sign",
@@ -157,12 +221,61 @@ func (h *HiveMock) GetTable(dbName string, tableName string) (*hive_metastore.Ta
 				Type: "bigint",
 			},
 		},
-		Parameters:     map[string]string{},
+		Parameters: map[string]string{
+			"comment":               "table comment",
+			"transient_lastDdlTime": "1644329244",
+			"parquet.compression":   "SNAPPY",
+		},
 		TableType:      "EXTERNAL_TABLE",
 		RewriteEnabled: boolPtr(false),
 	}, nil
 }
 
+func (h *HiveMock) GetTableObjects(dbName string, tableNames []string) ([]*hive_metastore.Table, error) {
+	tables := make([]*hive_metastore.Table, 0)
+	for _, tableName := range tableNames {
+		table, err := h.GetTable(dbName, tableName)
+		if err != nil {
+			continue
+		}
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
+
+func (h *HiveMock) GetPartitions(dbName string, tableName string, _ int) ([]*hive_metastore.Partition, error) {
+	if dbName != "pls" || tableName != "table" {
+		return nil, fmt.Errorf("NoSuchObject")
+	}
+	return []*hive_metastore.Partition{
+		{
+			Values:    []string{"1"},
+			DbName:    dbName,
+			TableName: tableName,
+			Sd:        &hive_metastore.StorageDescriptor{Location: "s3a://bucket/table/partition=1"},
+		},
+		{
+			Values:    []string{"2"},
+			DbName:    dbName,
+			TableName: tableName,
+			Sd:        &hive_metastore.StorageDescriptor{Location: "s3a://elsewhere/partition=2"},
+		},
+	}, nil
+}
+
+func (h *HiveMock) AddPartitions(newParts []*hive_metastore.Partition) error {
+	h.addPartitionCalls = append(h.addPartitionCalls, newParts...)
+	return nil
+}
+
+func (h *HiveMock) DropPartition(dbName string, tableName string, values []string, _ bool) (bool, error) {
+	h.dropPartitionCalls = append(h.dropPartitionCalls, values)
+	if values[0] == "err" {
+		return false, fmt.Errorf("could not drop partition")
+	}
+	return true, nil
+}
+
 func (h *HiveMock) Close() {}
 
 func TestHiveMetaStore_GetTableInfo(t *testing.T) {
@@ -193,8 +306,9 @@ func TestHiveMetaStore_GetTableInfo(t *testing.T) {
 				Name: "table",
 				Columns: []model.Column{
 					{
-						Name: "id",
-						Type: model.ColumnType{SqlType: model.BIGINT},
+						Name:    "id",
+						Type:    model.ColumnType{SqlType: model.BIGINT},
+						Comment: "primary key",
 					},
 					{
 						Name: "sign",
@@ -236,6 +350,9 @@ func TestHiveMetaStore_GetTableInfo(t *testing.T) {
 				},
 				MetadataLocation: "s3a://bucket/table",
 				Format:           model.PARQUET,
+				Parameters:       map[string]string{"parquet.compression": "SNAPPY"},
+				Description:      "table comment",
+				Owner:            "sap",
 			},
 			wantErr: false,
 		},
@@ -255,7 +372,8 @@ func TestHiveMetaStore_GetTableInfo(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			h := &HiveMetaStore{
-				hiveFactory: tt.fields.hiveFactory,
+				hiveFactory:        tt.fields.hiveFactory,
+				excludedParameters: []string{"transient_lastDdlTime"},
 			}
 			got, err := h.GetTableInfo(tt.args.dbName, tt.args.tableName)
 			if (err != nil) != tt.wantErr {
@@ -332,6 +450,60 @@ func TestHiveMetaStore_GetTables(t *testing.T) {
 	}
 }
 
+func TestHiveMetaStore_GetTablesInfo(t *testing.T) {
+	icebergTable := &hive_metastore.Table{
+		TableName:  "table",
+		DbName:     "pls",
+		Parameters: map[string]string{"table_type": "ICEBERG"},
+		Sd: &hive_metastore.StorageDescriptor{
+			Cols:     []*hive_metastore.FieldSchema{{Name: "id", Type: "bigint"}},
+			Location: "s3a://bucket/table",
+		},
+	}
+	tests := []struct {
+		name         string
+		hive         *HiveMock
+		dbName       string
+		wantLocation string
+		wantErr      bool
+	}{
+		{
+			name:         "shouldGetTables",
+			hive:         &HiveMock{},
+			dbName:       "pls",
+			wantLocation: "s3a://bucket/table",
+		},
+		{
+			name:         "shouldGetIcebergMetadataLocation",
+			hive:         &HiveMock{getTableOut: icebergTable},
+			dbName:       "pls",
+			wantLocation: "s3://bucket/table/metadata/00002-aaaa.metadata.json",
+		},
+		{
+			name:    "shouldErrorWhenDatabaseMissing",
+			hive:    &HiveMock{},
+			dbName:  "missing",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aux := &AuxMock{}
+			h := NewHiveMetaStore(&HiveFactoryMock{hive: tt.hive}, &MockFileDeleter{}, aux, nil)
+			got, err := h.GetTablesInfo(tt.dbName)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, got, 1)
+			require.Equal(t, "table", got[0].Name)
+			require.Equal(t, tt.wantLocation, got[0].MetadataLocation)
+			require.Equal(t, []string{"pls"}, aux.getTablesPropertyCalls)
+		})
+	}
+}
+
 func TestHiveMetaStore_CreateTable(t *testing.T) {
 	type fields struct {
 		hiveFactory HiveFactory
@@ -361,8 +533,9 @@ func TestHiveMetaStore_CreateTable(t *testing.T) {
 							Type: model.ColumnType{SqlType: model.BIGINT},
 						},
 						{
-							Name: "name",
-							Type: model.ColumnType{SqlType: model.VARCHAR, Length: 200},
+							Name:    "name",
+							Type:    model.ColumnType{SqlType: model.VARCHAR, Length: 200},
+							Comment: "customer name",
 						},
 					},
 					Partitions: []model.Column{
@@ -438,8 +611,9 @@ func TestHiveMetaStore_CreateTable(t *testing.T) {
 			require.Len(t, mock.createCalls[0].Sd.Cols, len(tt.args.table.Columns))
 			for i, column := range tt.args.table.Columns {
 				require.Equal(t, mock.createCalls[0].Sd.Cols[i], &hive_metastore.FieldSchema{
-					Name: column.Name,
-					Type: model.UnmapColumnType(column.Type),
+					Name:    column.Name,
+					Type:    model.UnmapColumnType(column.Type),
+					Comment: column.Comment,
 				})
 			}
 			require.Len(t, mock.createCalls[0].PartitionKeys, len(tt.args.table.Partitions))
@@ -453,6 +627,249 @@ func TestHiveMetaStore_CreateTable(t *testing.T) {
 	}
 }
 
+func TestHiveMetaStore_CreateTableParameters(t *testing.T) {
+	mock := &HiveMock{}
+	h := &HiveMetaStore{
+		hiveFactory: &HiveFactoryMock{hive: mock},
+	}
+	table := model.TableInfo{
+		Name: "table",
+		Columns: []model.Column{
+			{
+				Name: "id",
+				Type: model.ColumnType{SqlType: model.BIGINT},
+			},
+		},
+		MetadataLocation: "s3://bucket/table",
+		Format:           model.PARQUET,
+		Parameters:       map[string]string{"parquet.compression": "SNAPPY", "EXTERNAL": "FALSE"},
+		Description:      "table comment",
+		Owner:            "sap",
+	}
+	require.NoError(t, h.CreateTable("pls", table))
+	table.Name = "table2"
+	table.Parameters = nil
+	table.Description = ""
+	table.Owner = ""
+	require.NoError(t, h.CreateTable("pls", table))
+
+	require.Len(t, mock.createCalls, 2)
+	require.Equal(t, "sap", mock.createCalls[0].Owner)
+	require.Equal(t, map[string]string{
+		"parquet.compression": "SNAPPY",
+		"EXTERNAL":            "TRUE",
+		"comment":             "table comment",
+	}, mock.createCalls[0].Parameters)
+	require.Equal(t, "metaman", mock.createCalls[1].Owner)
+	require.Equal(t, map[string]string{"EXTERNAL": "TRUE"}, mock.createCalls[1].Parameters)
+}
+
+func TestHiveMetaStore_CreateTableHudiMor(t *testing.T) {
+	mock := &HiveMock{}
+	h := &HiveMetaStore{
+		hiveFactory: &HiveFactoryMock{hive: mock},
+	}
+	table := model.TableInfo{
+		Name: "table_ro",
+		Columns: []model.Column{
+			{
+				Name: "id",
+				Type: model.ColumnType{SqlType: model.BIGINT},
+			},
+		},
+		MetadataLocation: "s3://bucket/table",
+		Format:           model.HUDI_MOR,
+		Parameters:       map[string]string{"hoodie.table.name": "table"},
+	}
+	require.NoError(t, h.CreateTable("pls", table))
+	table.Name = "table_rt"
+	require.NoError(t, h.CreateTable("pls", table))
+
+	require.Len(t, mock.createCalls, 2)
+	ro, rt := mock.createCalls[0], mock.createCalls[1]
+	require.Equal(t, "org.apache.hudi.hadoop.HoodieParquetInputFormat", ro.Sd.InputFormat)
+	require.Equal(t, map[string]string{
+		"serialization.format":     "1",
+		"path":                     "s3a://bucket/table",
+		"hoodie.query.as.ro.table": "true",
+	}, ro.Sd.SerdeInfo.Parameters)
+	require.Equal(t, "org.apache.hudi.hadoop.realtime.HoodieParquetRealtimeInputFormat", rt.Sd.InputFormat)
+	require.Equal(t, "false", rt.Sd.SerdeInfo.Parameters["hoodie.query.as.ro.table"])
+	require.Equal(t, map[string]string{
+		"hoodie.table.name":          "table",
+		"EXTERNAL":                   "TRUE",
+		"spark.sql.sources.provider": "hudi",
+		"type":                       "mor",
+	}, rt.Parameters)
+	require.Equal(t, model.TableFormat(model.HUDI_MOR), model.FromTable(ro.Sd.InputFormat, ro.Sd.Location, ro.Parameters, unmapSerdeInfoHive(ro.Sd.SerdeInfo)))
+}
+
+func TestHiveMetaStore_UnknownFormatRoundTrip(t *testing.T) {
+	mock := &HiveMock{
+		getTableOut: &hive_metastore.Table{
+			TableName: "table",
+			DbName:    "pls",
+			Owner:     "sap",
+			Sd: &hive_metastore.StorageDescriptor{
+				Cols:         []*hive_metastore.FieldSchema{{Name: "id", Type: "bigint"}},
+				Location:     "s3a://bucket/table",
+				InputFormat:  "org.apache.hadoop.hive.ql.io.RCFileInputFormat",
+				OutputFormat: "org.apache.hadoop.hive.ql.io.RCFileOutputFormat",
+				SerdeInfo: &hive_metastore.SerDeInfo{
+					SerializationLib: "org.apache.hadoop.hive.serde2.columnar.LazyBinaryColumnarSerDe",
+					Parameters:       map[string]string{"serialization.format": "1"},
+				},
+			},
+			Parameters: map[string]string{"EXTERNAL": "TRUE", "custom": "value"},
+			TableType:  "EXTERNAL_TABLE",
+		},
+	}
+	h := &HiveMetaStore{
+		hiveFactory: &HiveFactoryMock{hive: mock},
+	}
+	info, err := h.GetTableInfo("pls", "table")
+	require.NoError(t, err)
+	require.Equal(t, model.TableFormat(model.UNKNOWN), info.Format)
+	require.NoError(t, h.CreateTable("pls", info))
+
+	require.Len(t, mock.createCalls, 1)
+	created := mock.createCalls[0]
+	require.Equal(t, mock.getTableOut.Sd.Location, created.Sd.Location)
+	require.Equal(t, mock.getTableOut.Sd.InputFormat, created.Sd.InputFormat)
+	require.Equal(t, mock.getTableOut.Sd.OutputFormat, created.Sd.OutputFormat)
+	require.Equal(t, mock.getTableOut.Sd.SerdeInfo.SerializationLib, created.Sd.SerdeInfo.SerializationLib)
+	require.Equal(t, mock.getTableOut.Sd.SerdeInfo.Parameters, created.Sd.SerdeInfo.Parameters)
+	require.Equal(t, mock.getTableOut.Parameters, created.Parameters)
+	require.Equal(t, mock.getTableOut.TableType, created.TableType)
+}
+
+func TestHiveMetaStore_CreateTableBucketing(t *testing.T) {
+	mock := &HiveMock{}
+	h := &HiveMetaStore{
+		hiveFactory: &HiveFactoryMock{hive: mock},
+	}
+	table := model.TableInfo{
+		Name:             "table",
+		Columns:          []model.Column{{Name: "id", Type: model.ColumnType{SqlType: model.BIGINT}}},
+		MetadataLocation: "s3://bucket/table",
+		Format:           model.ORC,
+		Bucketing: &model.Bucketing{
+			Columns:     []string{"id"},
+			NumBuckets:  8,
+			SortColumns: []model.SortColumn{{Name: "id", Ascending: true}},
+		},
+	}
+	require.NoError(t, h.CreateTable("pls", table))
+	table.Name = "table2"
+	table.Bucketing = nil
+	require.NoError(t, h.CreateTable("pls", table))
+
+	require.Len(t, mock.createCalls, 2)
+	sd := mock.createCalls[0].Sd
+	require.Equal(t, int32(8), sd.NumBuckets)
+	require.Equal(t, []*hive_metastore.Order{{Col: "id", Order: 1}}, sd.SortCols)
+	require.Equal(t, &model.Bucketing{Columns: []string{"id"}, NumBuckets: 8, SortColumns: []model.SortColumn{{Name: "id", Ascending: true}}}, mapBucketingHive(sd))
+	require.Equal(t, int32(-1), mock.createCalls[1].Sd.NumBuckets)
+	require.Nil(t, mapBucketingHive(mock.createCalls[1].Sd))
+}
+
+func TestHiveMetaStore_AlterTable(t *testing.T) {
+	type args struct {
+		dbName string
+		table  model.TableInfo
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "shouldAlterTable",
+			args: args{
+				dbName: "pls",
+				table: model.TableInfo{
+					Name: "table",
+					Columns: []model.Column{
+						{
+							Name: "id",
+							Type: model.ColumnType{SqlType: model.BIGINT},
+						},
+						{
+							Name: "name",
+							Type: model.ColumnType{SqlType: model.VARCHAR, Length: 200},
+						},
+					},
+					Partitions: []model.Column{
+						{
+							Name: "partition",
+							Type: model.ColumnType{SqlType: model.BIGINT},
+						},
+					},
+					MetadataLocation: "s3://bucket/table_v2",
+					Format:           model.PARQUET,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "shouldErrorWhenTableNotFound",
+			args: args{
+				dbName: "pls",
+				table: model.TableInfo{
+					Name: "missing",
+					Columns: []model.Column{
+						{
+							Name: "id",
+							Type: model.ColumnType{SqlType: model.BIGINT},
+						},
+					},
+					MetadataLocation: "s3://bucket/missing",
+					Format:           model.PARQUET,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "shouldErrorWhenNoColumnsSpecified",
+			args: args{
+				dbName: "pls",
+				table: model.TableInfo{
+					Name:             "table",
+					Columns:          []model.Column{},
+					MetadataLocation: "s3://bucket/table",
+					Format:           model.PARQUET,
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &HiveMock{}
+			h := &HiveMetaStore{
+				hiveFactory: &HiveFactoryMock{hive: mock},
+			}
+			if err := h.AlterTable(tt.args.dbName, tt.args.table); (err != nil) != tt.wantErr {
+				t.Errorf("AlterTable() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				require.Len(t, mock.alterCalls, 0)
+				return
+			}
+			require.Len(t, mock.alterCalls, 1)
+			require.Equal(t, tt.args.table.Name, mock.alterCalls[0].TableName)
+			require.Equal(t, tt.args.dbName, mock.alterCalls[0].DbName)
+			require.Equal(t, "sap", mock.alterCalls[0].Owner)
+			require.Equal(t, int32(1644329244), mock.alterCalls[0].CreateTime)
+			require.Equal(t, "s3a://bucket/table_v2", mock.alterCalls[0].Sd.Location)
+			require.Len(t, mock.alterCalls[0].Sd.Cols, len(tt.args.table.Columns))
+			require.Len(t, mock.alterCalls[0].PartitionKeys, len(tt.args.table.Partitions))
+			require.Equal(t, "TRUE", mock.alterCalls[0].Parameters["EXTERNAL"])
+		})
+	}
+}
+
 func TestHiveMetaStore_DropTable(t *testing.T) {
 	type fields struct {
 		hiveFactory HiveFactory
@@ -561,7 +978,7 @@ func TestHiveMetaStore_DropTable(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			h := NewHiveMetaStore(tt.fields.hiveFactory, tt.fields.fileDeleter, tt.fields.aux)
+			h := NewHiveMetaStore(tt.fields.hiveFactory, tt.fields.fileDeleter, tt.fields.aux, nil)
 			if err := h.DropTable(tt.args.dbName, tt.args.tableName, tt.args.deleteData); (err != nil) != tt.wantErr {
 				t.Errorf("DropTable() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -586,6 +1003,116 @@ func TestHiveMetaStore_DropTable(t *testing.T) {
 	}
 }
 
+func TestHiveMetaStore_GetPartitions(t *testing.T) {
+	h := &HiveMetaStore{
+		hiveFactory: &HiveFactoryMock{hive: &HiveMock{}},
+	}
+	got, err := h.GetPartitions("pls", "table")
+	require.NoError(t, err)
+	require.Equal(t, []model.Partition{
+		{Values: []string{"1"}, Location: "s3a://bucket/table/partition=1"},
+		{Values: []string{"2"}, Location: "s3a://elsewhere/partition=2"},
+	}, got)
+
+	_, err = h.GetPartitions("pls", "nope")
+	require.Error(t, err)
+}
+
+func TestHiveMetaStore_AddPartitions(t *testing.T) {
+	mock := &HiveMock{}
+	h := &HiveMetaStore{
+		hiveFactory: &HiveFactoryMock{hive: mock},
+	}
+	require.NoError(t, h.AddPartitions("pls", "table", []model.Partition{
+		{Values: []string{"1"}, Location: "s3://bucket/table/partition=1"},
+		{Values: []string{"2"}, Location: "s3://elsewhere/partition=2"},
+	}))
+	require.Len(t, mock.addPartitionCalls, 2)
+	require.Equal(t, []string{"1"}, mock.addPartitionCalls[0].Values)
+	require.Equal(t, "pls", mock.addPartitionCalls[0].DbName)
+	require.Equal(t, "table", mock.addPartitionCalls[0].TableName)
+	require.Equal(t, "s3a://bucket/table/partition=1", mock.addPartitionCalls[0].Sd.Location)
+	require.Equal(t, "s3a://elsewhere/partition=2", mock.addPartitionCalls[1].Sd.Location)
+	require.Equal(t, "org.apache.hadoop.hiveFactory.ql.io.parquet.MapredParquetInputFormat", mock.addPartitionCalls[1].Sd.InputFormat)
+	require.Len(t, mock.addPartitionCalls[1].Sd.Cols, 8)
+
+	require.Error(t, h.AddPartitions("pls", "nope", []model.Partition{{Values: []string{"1"}}}))
+	require.Len(t, mock.addPartitionCalls, 2)
+}
+
+func TestHiveMetaStore_DropPartitions(t *testing.T) {
+	mock := &HiveMock{}
+	h := &HiveMetaStore{
+		hiveFactory: &HiveFactoryMock{hive: mock},
+	}
+	require.Error(t, h.DropPartitions("pls", "table", []model.Partition{
+		{Values: []string{"1"}},
+		{Values: []string{"err"}},
+		{Values: []string{"2"}},
+	}))
+	require.Equal(t, [][]string{{"1"}, {"err"}, {"2"}}, mock.dropPartitionCalls)
+}
+
+func TestHiveMetaStore_GetDatabases(t *testing.T) {
+	h := &HiveMetaStore{
+		hiveFactory: &HiveFactoryMock{hive: &HiveMock{}},
+	}
+	got, err := h.GetDatabases()
+	require.NoError(t, err)
+	require.Equal(t, []string{"default", "pls"}, got)
+}
+
+func TestHiveMetaStore_GetDatabaseInfo(t *testing.T) {
+	h := &HiveMetaStore{
+		hiveFactory: &HiveFactoryMock{hive: &HiveMock{}},
+	}
+	got, err := h.GetDatabaseInfo("pls")
+	require.NoError(t, err)
+	require.Equal(t, model.Database{
+		Name:        "pls",
+		Description: "pls database",
+		Location:    "s3a://bucket/pls",
+		Parameters:  map[string]string{"team": "data"},
+	}, got)
+
+	_, err = h.GetDatabaseInfo("nodb")
+	require.Error(t, err)
+}
+
+func TestHiveMetaStore_CreateDatabase(t *testing.T) {
+	mock := &HiveMock{}
+	h := &HiveMetaStore{
+		hiveFactory: &HiveFactoryMock{hive: mock},
+	}
+	require.NoError(t, h.CreateDatabase(model.Database{
+		Name:        "pls",
+		Description: "pls database",
+		Location:    "s3://bucket/pls",
+		Parameters:  map[string]string{"team": "data"},
+	}))
+	require.Equal(t, []*hmsclient.Database{
+		{
+			Name:        "pls",
+			Description: "pls database",
+			Owner:       "metaman",
+			Location:    "s3a://bucket/pls",
+			Parameters:  map[string]string{"team": "data"},
+		},
+	}, mock.createDbCalls)
+
+	require.Error(t, h.CreateDatabase(model.Database{Name: "err"}))
+}
+
+func TestHiveMetaStore_DropDatabase(t *testing.T) {
+	mock := &HiveMock{}
+	h := &HiveMetaStore{
+		hiveFactory: &HiveFactoryMock{hive: mock},
+	}
+	require.NoError(t, h.DropDatabase("pls", true))
+	require.Error(t, h.DropDatabase("pls", false))
+	require.Equal(t, []DropCall{{dbName: "pls"}, {dbName: "pls"}}, mock.dropDbCalls)
+}
+
 func boolPtr(b bool) *bool {
 	return &b
 }
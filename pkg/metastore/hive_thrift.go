@@ -0,0 +1,146 @@
+package metastore
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"github.com/akolb1/gometastore/hmsclient"
+	"github.com/akolb1/gometastore/hmsclient/thrift/gen-go/hive_metastore"
+	"github.com/apache/thrift/lib/go/thrift"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	hiveConnectTimeout = 30 * time.Second
+	hiveBufferSize     = 1024 * 1024
+)
+
+// HiveTransportFactory opens connections over TLS when tlsConfig is set, authenticating
+// with sasl when set, which hmsclient.Open doesn't support.
+type HiveTransportFactory struct {
+	hiveHost  string
+	hivePort  int
+	tlsConfig *tls.Config
+	sasl      SaslMechanism
+}
+
+func NewHiveTransportFactory(hiveHost string, hivePort int, tlsConfig *tls.Config, sasl SaslMechanism) *HiveTransportFactory {
+	return &HiveTransportFactory{hiveHost: hiveHost, hivePort: hivePort, tlsConfig: tlsConfig, sasl: sasl}
+}
+
+func (h *HiveTransportFactory) GetHive() (Hive, error) {
+	address := net.JoinHostPort(h.hiveHost, strconv.Itoa(h.hivePort))
+	conf := &thrift.TConfiguration{ConnectTimeout: hiveConnectTimeout, TLSConfig: h.tlsConfig}
+	var transport thrift.TTransport
+	if h.tlsConfig != nil {
+		transport = thrift.NewTSSLSocketConf(address, conf)
+	} else {
+		transport = thrift.NewTSocketConf(address, conf)
+	}
+	if h.sasl != nil {
+		transport = newSaslTransport(transport, h.sasl, conf)
+	} else {
+		transport = thrift.NewTBufferedTransport(transport, hiveBufferSize)
+	}
+	if err := transport.Open(); err != nil {
+		return nil, fmt.Errorf("failed to open connection to %s: %w", address, err)
+	}
+	protocol := thrift.NewTBinaryProtocolConf(transport, conf)
+	return &HiveThriftClient{
+		context:   context.Background(),
+		transport: transport,
+		client:    hive_metastore.NewThriftHiveMetastoreClient(thrift.NewTStandardClient(protocol, protocol)),
+	}, nil
+}
+
+// HiveThriftClient is the Hive client over any thrift transport, as hmsclient.MetastoreClient.
+type HiveThriftClient struct {
+	context   context.Context
+	transport thrift.TTransport
+	client    *hive_metastore.ThriftHiveMetastoreClient
+}
+
+func (c *HiveThriftClient) GetAllDatabases() ([]string, error) {
+	return c.client.GetAllDatabases(c.context)
+}
+
+func (c *HiveThriftClient) GetDatabase(dbName string) (*hmsclient.Database, error) {
+	db, err := c.client.GetDatabase(c.context, dbName)
+	if err != nil {
+		return nil, err
+	}
+	result := &hmsclient.Database{
+		Name:        db.GetName(),
+		Description: db.GetDescription(),
+		Parameters:  db.GetParameters(),
+		Location:    db.GetLocationUri(),
+		Owner:       db.GetOwnerName(),
+	}
+	if db.OwnerType != nil {
+		result.OwnerType = *db.OwnerType
+	}
+	return result, nil
+}
+
+func (c *HiveThriftClient) CreateDatabase(db *hmsclient.Database) error {
+	database := &hive_metastore.Database{
+		Name:        db.Name,
+		Description: db.Description,
+		Parameters:  db.Parameters,
+		LocationUri: db.Location,
+	}
+	if db.Owner != "" {
+		database.OwnerName = &db.Owner
+	}
+	if db.OwnerType != 0 {
+		database.OwnerType = &db.OwnerType
+	}
+	return c.client.CreateDatabase(c.context, database)
+}
+
+func (c *HiveThriftClient) DropDatabase(dbName string, deleteData bool, cascade bool) error {
+	return c.client.DropDatabase(c.context, dbName, deleteData, cascade)
+}
+
+func (c *HiveThriftClient) GetTable(dbName string, tableName string) (*hive_metastore.Table, error) {
+	return c.client.GetTable(c.context, dbName, tableName)
+}
+
+func (c *HiveThriftClient) GetTableObjects(dbName string, tableNames []string) ([]*hive_metastore.Table, error) {
+	return c.client.GetTableObjectsByName(c.context, dbName, tableNames)
+}
+
+func (c *HiveThriftClient) GetAllTables(dbName string) ([]string, error) {
+	return c.client.GetAllTables(c.context, dbName)
+}
+
+func (c *HiveThriftClient) CreateTable(table *hive_metastore.Table) error {
+	return c.client.CreateTable(c.context, table)
+}
+
+func (c *HiveThriftClient) AlterTable(dbName string, tableName string, table *hive_metastore.Table) error {
+	return c.client.AlterTable(c.context, dbName, tableName, table)
+}
+
+func (c *HiveThriftClient) DropTable(dbName string, tableName string, deleteData bool) error {
+	return c.client.DropTable(c.context, dbName, tableName, deleteData)
+}
+
+func (c *HiveThriftClient) GetPartitions(dbName string, tableName string, maxCount int) ([]*hive_metastore.Partition, error) {
+	return c.client.GetPartitions(c.context, dbName, tableName, int16(maxCount))
+}
+
+func (c *HiveThriftClient) AddPartitions(newParts []*hive_metastore.Partition) error {
+	_, err := c.client.AddPartitions(c.context, newParts)
+	return err
+}
+
+func (c *HiveThriftClient) DropPartition(dbName string, tableName string, values []string, dropData bool) (bool, error) {
+	return c.client.DropPartition(c.context, dbName, tableName, values, dropData)
+}
+
+func (c *HiveThriftClient) Close() {
+	c.transport.Close()
+}
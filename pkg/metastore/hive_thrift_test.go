@@ -0,0 +1,249 @@
+package metastore
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"errors"
+	"github.com/akolb1/gometastore/hmsclient/thrift/gen-go/hive_metastore"
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/jcmturner/gokrb5/v8/gssapi"
+	"github.com/jcmturner/gokrb5/v8/iana/keyusage"
+	"github.com/jcmturner/gokrb5/v8/types"
+	"github.com/stretchr/testify/require"
+	"io"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+)
+
+// HiveMetastoreStandIn serves the few metastore calls the tests make, the others panic.
+type HiveMetastoreStandIn struct {
+	hive_metastore.ThriftHiveMetastore
+	databases []string
+}
+
+func (s *HiveMetastoreStandIn) GetAllDatabases(_ context.Context) ([]string, error) {
+	return s.databases, nil
+}
+
+func (s *HiveMetastoreStandIn) GetTable(_ context.Context, dbName string, tableName string) (*hive_metastore.Table, error) {
+	return nil, &hive_metastore.NoSuchObjectException{Message: dbName + "." + tableName + " table not found"}
+}
+
+// startHiveStandIn serves standIn on a local port, over TLS when tlsConfig is set,
+// authenticating with SASL PLAIN against username and password when set.
+func startHiveStandIn(t *testing.T, standIn *HiveMetastoreStandIn, tlsConfig *tls.Config, username string, password string) int {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+	t.Cleanup(func() { listener.Close() })
+	processor := hive_metastore.NewThriftHiveMetastoreProcessor(standIn)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				var transport thrift.TTransport = thrift.NewTSocketFromConnConf(conn, nil)
+				if username != "" {
+					if !acceptSaslPlain(conn, username, password) {
+						return
+					}
+					transport = thrift.NewTFramedTransportConf(transport, nil)
+				} else {
+					transport = thrift.NewTBufferedTransport(transport, hiveBufferSize)
+				}
+				protocol := thrift.NewTBinaryProtocolConf(transport, nil)
+				for {
+					if _, err := processor.Process(context.Background(), protocol, protocol); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func acceptSaslPlain(conn net.Conn, username string, password string) bool {
+	read := func() (byte, []byte) {
+		header := make([]byte, 5)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return 0, nil
+		}
+		payload := make([]byte, binary.BigEndian.Uint32(header[1:]))
+		if _, err := io.ReadFull(conn, payload); err != nil {
+			return 0, nil
+		}
+		return header[0], payload
+	}
+	write := func(status byte, payload string) {
+		header := make([]byte, 5)
+		header[0] = status
+		binary.BigEndian.PutUint32(header[1:], uint32(len(payload)))
+		conn.Write(append(header, payload...))
+	}
+	if status, mechanism := read(); status != saslStart || string(mechanism) != "PLAIN" {
+		write(saslBad, "unsupported mechanism")
+		return false
+	}
+	if status, response := read(); status != saslOk || string(response) != "\x00"+username+"\x00"+password {
+		write(saslBad, "authentication failed")
+		return false
+	}
+	write(saslComplete, "")
+	return true
+}
+
+func selfSignedTls(t *testing.T) (*tls.Config, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "hive-metastore"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}, pool
+}
+
+func TestHiveTransportFactory_GetHive(t *testing.T) {
+	serverTls, ca := selfSignedTls(t)
+	_, otherCa := selfSignedTls(t)
+	tests := []struct {
+		name       string
+		serverTls  *tls.Config
+		serverUser string
+		serverPass string
+		clientTls  *tls.Config
+		sasl       SaslMechanism
+		wantErr    bool
+	}{
+		{
+			name: "shouldConnectPlainSocket",
+		},
+		{
+			name:      "shouldConnectTls",
+			serverTls: serverTls,
+			clientTls: &tls.Config{RootCAs: ca},
+		},
+		{
+			name:       "shouldAuthenticateSaslPlain",
+			serverUser: "metaman",
+			serverPass: "secret",
+			sasl:       NewSaslPlain("metaman", "secret"),
+		},
+		{
+			name:       "shouldAuthenticateSaslPlainOverTls",
+			serverTls:  serverTls,
+			serverUser: "metaman",
+			serverPass: "secret",
+			clientTls:  &tls.Config{RootCAs: ca},
+			sasl:       NewSaslPlain("metaman", "secret"),
+		},
+		{
+			name:       "shouldFailWrongPassword",
+			serverUser: "metaman",
+			serverPass: "secret",
+			sasl:       NewSaslPlain("metaman", "wrong"),
+			wantErr:    true,
+		},
+		{
+			name:      "shouldFailUntrustedCertificate",
+			serverTls: serverTls,
+			clientTls: &tls.Config{RootCAs: otherCa},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			standIn := &HiveMetastoreStandIn{databases: []string{"default", "pls"}}
+			port := startHiveStandIn(t, standIn, tt.serverTls, tt.serverUser, tt.serverPass)
+			hive, err := NewHiveTransportFactory("127.0.0.1", port, tt.clientTls, tt.sasl).GetHive()
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer hive.Close()
+			databases, err := hive.GetAllDatabases()
+			require.NoError(t, err)
+			require.Equal(t, []string{"default", "pls"}, databases)
+			_, err = hive.GetTable("pls", "missing")
+			var notFound *hive_metastore.NoSuchObjectException
+			require.True(t, errors.As(err, &notFound))
+			require.False(t, isTransportError(err))
+		})
+	}
+}
+
+func TestGssapiSecurityLayer(t *testing.T) {
+	key := types.EncryptionKey{KeyType: 18, KeyValue: make([]byte, 32)}
+	offer := func(layers byte) []byte {
+		token := gssapi.WrapToken{Flags: 0x01 | 0x04, EC: 12, Payload: []byte{layers, 0, 0x10, 0}}
+		require.NoError(t, token.SetCheckSum(key, keyusage.GSSAPI_ACCEPTOR_SEAL))
+		b, err := token.Marshal()
+		require.NoError(t, err)
+		return b
+	}
+	tests := []struct {
+		name      string
+		challenge []byte
+		wantErr   bool
+	}{
+		{
+			name:      "shouldSelectNoSecurityLayer",
+			challenge: offer(0x07),
+		},
+		{
+			name:      "shouldFailWhenSecurityLayerRequired",
+			challenge: offer(0x04),
+			wantErr:   true,
+		},
+		{
+			name:      "shouldFailInvalidToken",
+			challenge: []byte{0x05, 0x04, 0x01},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := gssapiSecurityLayer(tt.challenge, key, true)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			var answer gssapi.WrapToken
+			require.NoError(t, answer.Unmarshal(got, false))
+			ok, err := answer.Verify(key, keyusage.GSSAPI_INITIATOR_SEAL)
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, byte(0x04), answer.Flags)
+			require.Equal(t, []byte{0x01, 0, 0, 0}, answer.Payload)
+		})
+	}
+}
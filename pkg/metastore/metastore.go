@@ -3,42 +3,109 @@ package metastore
 import (
 	"fmt"
 	"github.com/the-Data-Appeal-Company/metaman/pkg/model"
+	"sort"
 )
 
+// MetastoreCode names a metastore instance, e.g. hive-prod, or a metastore type.
 type MetastoreCode string
 
+// metastore types
 const (
 	HIVE MetastoreCode = "hive"
 	GLUE MetastoreCode = "glue"
+
+	ICEBERG_REST MetastoreCode = "iceberg_rest"
+	HIVE_DB      MetastoreCode = "hive_db"
+	FILE         MetastoreCode = "file"
 )
 
 type Metastore interface {
+	GetDatabases() ([]string, error)
+	GetDatabaseInfo(dbName string) (model.Database, error)
+	CreateDatabase(database model.Database) error
+	DropDatabase(dbName string, cascade bool) error
 	GetTables(dbName string) ([]string, error)
 	GetTableInfo(dbName, tableName string) (model.TableInfo, error)
 	CreateTable(dbName string, table model.TableInfo) error
+	AlterTable(dbName string, table model.TableInfo) error
 	DropTable(dbName string, tableName string, deleteData bool) error
+	GetPartitions(dbName, tableName string) ([]model.Partition, error)
+	AddPartitions(dbName, tableName string, partitions []model.Partition) error
+	DropPartitions(dbName, tableName string, partitions []model.Partition) error
+}
+
+// BulkReader is implemented by metastores able to read all the tables of a database at once,
+// tables that cannot be read are left out and reported in the returned error.
+type BulkReader interface {
+	GetTablesInfo(dbName string) ([]model.TableInfo, error)
+}
+
+// Properties are the parts of model.TableInfo a metastore keeps besides the table definition.
+type Properties struct {
+	Description      bool
+	TableParameters  bool
+	SerdeParameters  bool
+	ColumnParameters bool
+}
+
+// PropertiesKeeper is implemented by metastores keeping only part of the table properties,
+// on sync the properties are compared only when both metastores keep them.
+type PropertiesKeeper interface {
+	KeptProperties() Properties
+}
+
+// PropertiesOf returns the table properties kept by the metastore, all of them unless it tells otherwise.
+func PropertiesOf(metastore Metastore) Properties {
+	if keeper, ok := metastore.(PropertiesKeeper); ok {
+		return keeper.KeptProperties()
+	}
+	return Properties{Description: true, TableParameters: true, SerdeParameters: true, ColumnParameters: true}
+}
+
+// FormatHolder is implemented by metastores holding only some table formats,
+// on sync the tables of the other formats are skipped.
+type FormatHolder interface {
+	HoldsFormat(format model.TableFormat) bool
+}
+
+// HoldsFormat tells whether the metastore holds tables of the format, all of them unless it tells otherwise.
+func HoldsFormat(metastore Metastore, format model.TableFormat) bool {
+	if holder, ok := metastore.(FormatHolder); ok {
+		return holder.HoldsFormat(format)
+	}
+	return true
 }
 
 type Pool interface {
 	Get(metastore MetastoreCode) (Metastore, error)
+	Codes() []MetastoreCode
 }
 
+// PoolMetastore holds the configured metastore instances by name.
 type PoolMetastore struct {
-	hive Metastore
-	glue Metastore
+	metastores map[MetastoreCode]Metastore
 }
 
-func NewPoolMetastore(hive Metastore, glue Metastore) *PoolMetastore {
-	return &PoolMetastore{hive: hive, glue: glue}
+func NewPoolMetastore(metastores map[MetastoreCode]Metastore) *PoolMetastore {
+	return &PoolMetastore{metastores: metastores}
 }
 
 func (f *PoolMetastore) Get(metastore MetastoreCode) (Metastore, error) {
-	switch metastore {
-	case GLUE:
-		return f.glue, nil
-	case HIVE:
-		return f.hive, nil
-	default:
+	meta, ok := f.metastores[metastore]
+	if !ok {
 		return nil, fmt.Errorf("could not get '%s' metastore", metastore)
 	}
+	return meta, nil
+}
+
+// Codes returns the names of the metastore instances, sorted.
+func (f *PoolMetastore) Codes() []MetastoreCode {
+	codes := make([]MetastoreCode, 0, len(f.metastores))
+	for code := range f.metastores {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		return codes[i] < codes[j]
+	})
+	return codes
 }
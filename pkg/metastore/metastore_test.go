@@ -10,6 +10,22 @@ type NamedMetastoreMock struct {
 	name string
 }
 
+func (m *NamedMetastoreMock) GetDatabases() ([]string, error) {
+	return nil, nil
+}
+
+func (m *NamedMetastoreMock) GetDatabaseInfo(dbName string) (model.Database, error) {
+	return model.Database{}, nil
+}
+
+func (m *NamedMetastoreMock) CreateDatabase(database model.Database) error {
+	return nil
+}
+
+func (m *NamedMetastoreMock) DropDatabase(dbName string, cascade bool) error {
+	return nil
+}
+
 func (m *NamedMetastoreMock) GetTables(dbName string) ([]string, error) {
 	return nil, nil
 }
@@ -22,74 +38,83 @@ func (m *NamedMetastoreMock) CreateTable(dbName string, table model.TableInfo) e
 	return nil
 }
 
+func (m *NamedMetastoreMock) AlterTable(dbName string, table model.TableInfo) error {
+	return nil
+}
+
 func (m *NamedMetastoreMock) DropTable(dbName string, tableName string, deleteData bool) error {
 	return nil
 }
 
+func (m *NamedMetastoreMock) GetPartitions(dbName, tableName string) ([]model.Partition, error) {
+	return nil, nil
+}
+
+func (m *NamedMetastoreMock) AddPartitions(dbName, tableName string, partitions []model.Partition) error {
+	return nil
+}
+
+func (m *NamedMetastoreMock) DropPartitions(dbName, tableName string, partitions []model.Partition) error {
+	return nil
+}
+
 func TestPoolMetastore_Get(t *testing.T) {
-	type args struct {
-		metastore MetastoreCode
-	}
-	type fields struct {
-		hive Metastore
-		glue Metastore
+	metastores := map[MetastoreCode]Metastore{
+		"hive-prod":      &NamedMetastoreMock{name: "hiveProd"},
+		"hive-dr":        &NamedMetastoreMock{name: "hiveDr"},
+		"glue-eu-west-1": &NamedMetastoreMock{name: "glueEu"},
 	}
 	tests := []struct {
-		name    string
-		args    args
-		fields  fields
-		want    string
-		wantErr bool
+		name      string
+		metastore MetastoreCode
+		want      string
+		wantErr   bool
 	}{
 		{
-			name: "shouldGetHive",
-			args: args{
-				metastore: HIVE,
-			},
-			fields: fields{
-				hive: &NamedMetastoreMock{name: "hiveFactory"},
-				glue: &NamedMetastoreMock{name: "glue"},
-			},
-			want:    "hiveFactory",
-			wantErr: false,
+			name:      "shouldGetHive",
+			metastore: "hive-prod",
+			want:      "hiveProd",
+		},
+		{
+			name:      "shouldGetOtherHive",
+			metastore: "hive-dr",
+			want:      "hiveDr",
+		},
+		{
+			name:      "shouldGetGlue",
+			metastore: "glue-eu-west-1",
+			want:      "glueEu",
 		},
 		{
-			name: "shouldGetGlue",
-			args: args{
-				metastore: GLUE,
-			},
-			fields: fields{
-				hive: &NamedMetastoreMock{name: "hiveFactory"},
-				glue: &NamedMetastoreMock{name: "glue"},
-			},
-			want:    "glue",
-			wantErr: false,
+			name:      "shouldErrorWhenTypeIsNotAnInstance",
+			metastore: HIVE,
+			wantErr:   true,
 		},
 		{
-			name: "shouldErrorWhenNoSupportedMetastore",
-			args: args{
-				metastore: "no",
-			},
-			fields: fields{
-				hive: &NamedMetastoreMock{name: "hiveFactory"},
-				glue: &NamedMetastoreMock{name: "glue"},
-			},
-			want:    "",
-			wantErr: true,
+			name:      "shouldErrorWhenNotConfigured",
+			metastore: "no",
+			wantErr:   true,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			f := NewPoolMetastore(tt.fields.hive, tt.fields.glue)
-			got, err := f.Get(tt.args.metastore)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			f := NewPoolMetastore(metastores)
+			got, err := f.Get(tt.metastore)
+			if tt.wantErr {
+				require.Error(t, err)
 				return
 			}
-			if !tt.wantErr {
-				mock := got.(*NamedMetastoreMock)
-				require.Equal(t, tt.want, mock.name)
-			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got.(*NamedMetastoreMock).name)
 		})
 	}
 }
+
+func TestPoolMetastore_Codes(t *testing.T) {
+	f := NewPoolMetastore(map[MetastoreCode]Metastore{
+		"hive-prod":      &NamedMetastoreMock{},
+		"glue-eu-west-1": &NamedMetastoreMock{},
+		"hive-dr":        &NamedMetastoreMock{},
+	})
+	require.Equal(t, []MetastoreCode{"glue-eu-west-1", "hive-dr", "hive-prod"}, f.Codes())
+}
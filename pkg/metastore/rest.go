@@ -0,0 +1,367 @@
+package metastore
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/hashicorp/go-multierror"
+	"github.com/sirupsen/logrus"
+	"github.com/the-Data-Appeal-Company/metaman/pkg/iceberg"
+	"github.com/the-Data-Appeal-Company/metaman/pkg/model"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// icebergRestNamespaceSeparator joins the levels of a multi-level namespace in request paths,
+	// metaman databases name them joined by dots.
+	icebergRestNamespaceSeparator = "\x1f"
+	icebergRestLocationProperty   = "location"
+	icebergRestCommentProperty    = "comment"
+)
+
+// IcebergRestMetaStore is a metastore backed by an iceberg REST catalog, it only holds iceberg tables.
+type IcebergRestMetaStore struct {
+	client             *http.Client
+	baseUrl            string
+	token              string
+	excludedParameters []string
+}
+
+// NewIcebergRestMetaStore creates the metastore for the catalog at uri, prefix is the optional
+// catalog path prefix and token the optional bearer token sent along every request.
+func NewIcebergRestMetaStore(client *http.Client, uri, prefix, token string, excludedParameters []string) *IcebergRestMetaStore {
+	baseUrl := strings.TrimSuffix(uri, "/") + "/v1"
+	if prefix != "" {
+		baseUrl += "/" + url.PathEscape(prefix)
+	}
+	return &IcebergRestMetaStore{client: client, baseUrl: baseUrl, token: token, excludedParameters: excludedParameters}
+}
+
+type icebergRestNamespace struct {
+	Namespace  []string          `json:"namespace"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type icebergRestNamespaces struct {
+	Namespaces    [][]string `json:"namespaces"`
+	NextPageToken string     `json:"next-page-token"`
+}
+
+type icebergRestIdentifier struct {
+	Namespace []string `json:"namespace"`
+	Name      string   `json:"name"`
+}
+
+type icebergRestTables struct {
+	Identifiers   []icebergRestIdentifier `json:"identifiers"`
+	NextPageToken string                  `json:"next-page-token"`
+}
+
+type icebergRestTable struct {
+	MetadataLocation string                `json:"metadata-location"`
+	Metadata         iceberg.TableMetadata `json:"metadata"`
+}
+
+type icebergRestRegisterTable struct {
+	Name             string `json:"name"`
+	MetadataLocation string `json:"metadata-location"`
+}
+
+type icebergRestErrorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+		Code    int    `json:"code"`
+	} `json:"error"`
+}
+
+type icebergRestError struct {
+	StatusCode int
+	Type       string
+	Message    string
+}
+
+func (e *icebergRestError) Error() string {
+	return fmt.Sprintf("iceberg rest catalog: %d %s: %s", e.StatusCode, e.Type, e.Message)
+}
+
+func isIcebergRestNotFound(err error) bool {
+	var restErr *icebergRestError
+	return errors.As(err, &restErr) && restErr.StatusCode == http.StatusNotFound
+}
+
+func (r *IcebergRestMetaStore) GetDatabases() ([]string, error) {
+	dbs := make([]string, 0)
+	pageToken := ""
+	for {
+		var namespaces icebergRestNamespaces
+		err := r.do(http.MethodGet, "/namespaces", pageQuery(pageToken), nil, &namespaces)
+		if err != nil {
+			return nil, err
+		}
+		for _, namespace := range namespaces.Namespaces {
+			dbs = append(dbs, strings.Join(namespace, "."))
+		}
+		if namespaces.NextPageToken == "" {
+			return dbs, nil
+		}
+		pageToken = namespaces.NextPageToken
+	}
+}
+
+func (r *IcebergRestMetaStore) GetDatabaseInfo(dbName string) (model.Database, error) {
+	var namespace icebergRestNamespace
+	err := r.do(http.MethodGet, namespacePath(dbName), nil, nil, &namespace)
+	if err != nil {
+		return model.Database{}, err
+	}
+	parameters := filterParameters(namespace.Properties, []string{icebergRestLocationProperty, icebergRestCommentProperty})
+	return model.Database{
+		Name:        dbName,
+		Description: namespace.Properties[icebergRestCommentProperty],
+		Location:    namespace.Properties[icebergRestLocationProperty],
+		Parameters:  parameters,
+	}, nil
+}
+
+func (r *IcebergRestMetaStore) CreateDatabase(database model.Database) error {
+	properties := mergeParameters(nil, database.Parameters)
+	if database.Location != "" {
+		properties[icebergRestLocationProperty] = convertS3Format(ICEBERG_REST, database.Location)
+	}
+	if database.Description != "" {
+		properties[icebergRestCommentProperty] = database.Description
+	}
+	return r.do(http.MethodPost, "/namespaces", nil, icebergRestNamespace{
+		Namespace:  strings.Split(database.Name, "."),
+		Properties: properties,
+	}, nil)
+}
+
+// DropDatabase drops the namespace, with cascade its tables are dropped first keeping their files
+// as the catalog only drops empty namespaces.
+func (r *IcebergRestMetaStore) DropDatabase(dbName string, cascade bool) error {
+	tables, err := r.GetTables(dbName)
+	if err != nil {
+		return err
+	}
+	if len(tables) > 0 && !cascade {
+		return fmt.Errorf("database %s is not empty", dbName)
+	}
+	for _, table := range tables {
+		if err := r.DropTable(dbName, table, false); err != nil {
+			return err
+		}
+	}
+	return r.do(http.MethodDelete, namespacePath(dbName), nil, nil, nil)
+}
+
+func (r *IcebergRestMetaStore) GetTables(dbName string) ([]string, error) {
+	ts := make([]string, 0)
+	pageToken := ""
+	for {
+		var tables icebergRestTables
+		err := r.do(http.MethodGet, namespacePath(dbName)+"/tables", pageQuery(pageToken), nil, &tables)
+		if err != nil {
+			return nil, err
+		}
+		for _, identifier := range tables.Identifiers {
+			ts = append(ts, identifier.Name)
+		}
+		if tables.NextPageToken == "" {
+			return ts, nil
+		}
+		pageToken = tables.NextPageToken
+	}
+}
+
+// GetTableInfo loads the table, its columns and parameters come from the current
+// schema and properties of its metadata.
+func (r *IcebergRestMetaStore) GetTableInfo(dbName, tableName string) (model.TableInfo, error) {
+	var table icebergRestTable
+	err := r.do(http.MethodGet, tablePath(dbName, tableName), nil, nil, &table)
+	if err != nil {
+		return model.TableInfo{}, err
+	}
+	info := model.TableInfo{
+		Name:             tableName,
+		MetadataLocation: table.MetadataLocation,
+		Format:           model.ICEBERG,
+	}
+	if err := iceberg.ApplySchema(&info, table.Metadata); err != nil {
+		return model.TableInfo{}, fmt.Errorf("table %s: %s", tableName, err.Error())
+	}
+	info.Description = info.Parameters[icebergRestCommentProperty]
+	info.Parameters = filterParameters(info.Parameters, r.excludedParameters)
+	return info, nil
+}
+
+// CreateTable registers the table on its metadata file, schema and properties are the ones of the metadata.
+func (r *IcebergRestMetaStore) CreateTable(dbName string, table model.TableInfo) error {
+	metadataLocation, err := icebergRestMetadataLocation(table)
+	if err != nil {
+		return err
+	}
+	return r.register(dbName, table.Name, metadataLocation)
+}
+
+func (r *IcebergRestMetaStore) register(dbName, tableName, metadataLocation string) error {
+	return r.do(http.MethodPost, namespacePath(dbName)+"/register", nil, icebergRestRegisterTable{
+		Name:             tableName,
+		MetadataLocation: metadataLocation,
+	}, nil)
+}
+
+// AlterTable points the table to a new metadata file: the catalog only changes tables by committing
+// metadata updates, so the table is dropped keeping its files and registered again.
+func (r *IcebergRestMetaStore) AlterTable(dbName string, table model.TableInfo) error {
+	metadataLocation, err := icebergRestMetadataLocation(table)
+	if err != nil {
+		return err
+	}
+	var current icebergRestTable
+	err = r.do(http.MethodGet, tablePath(dbName, table.Name), nil, nil, &current)
+	if err != nil {
+		return err
+	}
+	if current.MetadataLocation == metadataLocation {
+		return nil
+	}
+	err = r.DropTable(dbName, table.Name, false)
+	if err != nil {
+		return err
+	}
+	err = r.register(dbName, table.Name, metadataLocation)
+	if err != nil {
+		logrus.Errorf("table %s dropped from iceberg rest catalog but could not be registered on %s, restoring %s", table.Name, metadataLocation, current.MetadataLocation)
+		if restoreErr := r.register(dbName, table.Name, current.MetadataLocation); restoreErr != nil {
+			return multierror.Append(err, fmt.Errorf("table %s could not be registered again on %s: %w", table.Name, current.MetadataLocation, restoreErr))
+		}
+		return err
+	}
+	return nil
+}
+
+// DropTable drops the table, with deleteData the catalog is asked to purge its files.
+func (r *IcebergRestMetaStore) DropTable(dbName string, tableName string, deleteData bool) error {
+	query := url.Values{}
+	if deleteData {
+		query.Set("purgeRequested", "true")
+	}
+	err := r.do(http.MethodDelete, tablePath(dbName, tableName), query, nil, nil)
+	if isIcebergRestNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// KeptProperties tells that no table property is kept, tables are registered on their metadata file only.
+func (r *IcebergRestMetaStore) KeptProperties() Properties {
+	return Properties{}
+}
+
+// HoldsFormat tells that the catalog only holds iceberg tables.
+func (r *IcebergRestMetaStore) HoldsFormat(format model.TableFormat) bool {
+	return format == model.ICEBERG
+}
+
+// GetPartitions returns no partitions, iceberg partitioning is hidden and not kept in the catalog.
+func (r *IcebergRestMetaStore) GetPartitions(dbName, tableName string) ([]model.Partition, error) {
+	return []model.Partition{}, nil
+}
+
+func (r *IcebergRestMetaStore) AddPartitions(dbName, tableName string, partitions []model.Partition) error {
+	if len(partitions) == 0 {
+		return nil
+	}
+	return fmt.Errorf("table %s: iceberg rest catalog tables have no partitions", tableName)
+}
+
+func (r *IcebergRestMetaStore) DropPartitions(dbName, tableName string, partitions []model.Partition) error {
+	if len(partitions) == 0 {
+		return nil
+	}
+	return fmt.Errorf("table %s: iceberg rest catalog tables have no partitions", tableName)
+}
+
+func (r *IcebergRestMetaStore) do(method, path string, query url.Values, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	endpoint := r.baseUrl + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(method, endpoint, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if r.token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.token)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		restErr := &icebergRestError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(data))}
+		var errorResponse icebergRestErrorResponse
+		if json.Unmarshal(data, &errorResponse) == nil && errorResponse.Error.Message != "" {
+			restErr.Type = errorResponse.Error.Type
+			restErr.Message = errorResponse.Error.Message
+		}
+		return restErr
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	err = json.Unmarshal(data, out)
+	if err != nil {
+		return fmt.Errorf("invalid iceberg rest catalog response for %s %s: %s", method, path, err.Error())
+	}
+	return nil
+}
+
+func icebergRestMetadataLocation(table model.TableInfo) (string, error) {
+	if table.Format != model.ICEBERG {
+		return "", fmt.Errorf("table %s: iceberg rest catalog only holds iceberg tables, got %s", table.Name, table.Format)
+	}
+	location := convertS3Format(ICEBERG_REST, table.MetadataLocation)
+	if _, ok := model.IcebergMetadataVersion(location); !ok {
+		return "", fmt.Errorf("table %s: iceberg rest catalog registers tables on a metadata file, got %s", table.Name, location)
+	}
+	return location, nil
+}
+
+func namespacePath(dbName string) string {
+	return "/namespaces/" + url.PathEscape(strings.ReplaceAll(dbName, ".", icebergRestNamespaceSeparator))
+}
+
+func tablePath(dbName, tableName string) string {
+	return namespacePath(dbName) + "/tables/" + url.PathEscape(tableName)
+}
+
+func pageQuery(pageToken string) url.Values {
+	if pageToken == "" {
+		return nil
+	}
+	return url.Values{"pageToken": []string{pageToken}}
+}
@@ -0,0 +1,330 @@
+package metastore
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"github.com/the-Data-Appeal-Company/metaman/pkg/model"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const restTableMetadata = `{
+  "format-version": 2,
+  "location": "s3://bucket/pls/events",
+  "current-schema-id": 0,
+  "schemas": [{"schema-id": 0, "fields": [
+    {"id": 1, "name": "id", "required": true, "type": "long"},
+    {"id": 2, "name": "payload", "required": false, "type": "string", "doc": "raw event"}
+  ]}],
+  "properties": {"comment": "events table", "write.format.default": "parquet"}
+}`
+
+// IcebergRestCatalogStandIn is an in memory iceberg rest catalog serving the endpoints used by the metastore.
+type IcebergRestCatalogStandIn struct {
+	namespaces map[string]map[string]string
+	tables     map[string]map[string]string
+	requests   []string
+	purged     []string
+	// unreadable are the metadata locations the catalog fails to register
+	unreadable map[string]bool
+}
+
+func NewIcebergRestCatalogStandIn() *IcebergRestCatalogStandIn {
+	return &IcebergRestCatalogStandIn{
+		namespaces: map[string]map[string]string{
+			"pls":         {"location": "s3://bucket/pls", "comment": "pls namespace", "team": "data"},
+			"lake\x1fraw": {},
+			"empty":       {},
+		},
+		tables: map[string]map[string]string{
+			"pls":   {"events": "s3://bucket/pls/events/metadata/00003-aaaa.metadata.json", "clicks": "s3://bucket/pls/clicks/metadata/00001-bbbb.metadata.json"},
+			"empty": {},
+		},
+	}
+}
+
+func (c *IcebergRestCatalogStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.requests = append(c.requests, r.Method+" "+r.URL.EscapedPath()+"?"+r.URL.RawQuery)
+	if r.Header.Get("Authorization") != "Bearer secret" {
+		writeRestError(w, http.StatusUnauthorized, "NotAuthorizedException", "invalid token")
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/warehouse/namespaces"), "/")
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		if r.URL.Query().Get("pageToken") == "" {
+			writeRestJson(w, map[string]interface{}{"namespaces": [][]string{{"pls"}}, "next-page-token": "next"})
+			return
+		}
+		writeRestJson(w, map[string]interface{}{"namespaces": [][]string{{"lake", "raw"}}, "next-page-token": nil})
+	case len(parts) == 1 && r.Method == http.MethodPost:
+		var namespace icebergRestNamespace
+		_ = json.NewDecoder(r.Body).Decode(&namespace)
+		c.namespaces[strings.Join(namespace.Namespace, "\x1f")] = namespace.Properties
+		writeRestJson(w, namespace)
+	case len(parts) == 2 && r.Method == http.MethodGet:
+		properties, ok := c.namespaces[parts[1]]
+		if !ok {
+			writeRestError(w, http.StatusNotFound, "NoSuchNamespaceException", "namespace not found")
+			return
+		}
+		writeRestJson(w, icebergRestNamespace{Namespace: strings.Split(parts[1], "\x1f"), Properties: properties})
+	case len(parts) == 2 && r.Method == http.MethodDelete:
+		if len(c.tables[parts[1]]) > 0 {
+			writeRestError(w, http.StatusConflict, "NamespaceNotEmptyException", "namespace not empty")
+			return
+		}
+		delete(c.namespaces, parts[1])
+		w.WriteHeader(http.StatusNoContent)
+	case len(parts) == 3 && parts[2] == "tables":
+		identifiers := make([]icebergRestIdentifier, 0)
+		for name := range c.tables[parts[1]] {
+			identifiers = append(identifiers, icebergRestIdentifier{Namespace: []string{parts[1]}, Name: name})
+		}
+		writeRestJson(w, icebergRestTables{Identifiers: identifiers})
+	case len(parts) == 3 && parts[2] == "register":
+		var register icebergRestRegisterTable
+		_ = json.NewDecoder(r.Body).Decode(&register)
+		if _, ok := c.tables[parts[1]][register.Name]; ok {
+			writeRestError(w, http.StatusConflict, "AlreadyExistsException", "table already exists")
+			return
+		}
+		if c.unreadable[register.MetadataLocation] {
+			writeRestError(w, http.StatusBadRequest, "BadRequestException", "cannot read metadata")
+			return
+		}
+		c.tables[parts[1]][register.Name] = register.MetadataLocation
+		c.writeTable(w, register.MetadataLocation)
+	case len(parts) == 4 && r.Method == http.MethodGet:
+		location, ok := c.tables[parts[1]][parts[3]]
+		if !ok {
+			writeRestError(w, http.StatusNotFound, "NoSuchTableException", "table not found")
+			return
+		}
+		c.writeTable(w, location)
+	case len(parts) == 4 && r.Method == http.MethodDelete:
+		if _, ok := c.tables[parts[1]][parts[3]]; !ok {
+			writeRestError(w, http.StatusNotFound, "NoSuchTableException", "table not found")
+			return
+		}
+		delete(c.tables[parts[1]], parts[3])
+		if r.URL.Query().Get("purgeRequested") == "true" {
+			c.purged = append(c.purged, parts[3])
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeRestError(w, http.StatusBadRequest, "BadRequestException", "unexpected request")
+	}
+}
+
+func (c *IcebergRestCatalogStandIn) writeTable(w http.ResponseWriter, location string) {
+	writeRestJson(w, map[string]interface{}{
+		"metadata-location": location,
+		"metadata":          json.RawMessage(restTableMetadata),
+	})
+}
+
+func writeRestJson(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeRestError(w http.ResponseWriter, status int, errorType, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = fmt.Fprintf(w, `{"error":{"message":%q,"type":%q,"code":%d}}`, message, errorType, status)
+}
+
+func newRestMetaStore(t *testing.T) (*IcebergRestMetaStore, *IcebergRestCatalogStandIn) {
+	catalog := NewIcebergRestCatalogStandIn()
+	server := httptest.NewServer(catalog)
+	t.Cleanup(server.Close)
+	return NewIcebergRestMetaStore(server.Client(), server.URL, "warehouse", "secret", []string{"write.format.default"}), catalog
+}
+
+func TestIcebergRestMetaStore_GetDatabases(t *testing.T) {
+	r, _ := newRestMetaStore(t)
+	got, err := r.GetDatabases()
+	require.NoError(t, err)
+	require.Equal(t, []string{"pls", "lake.raw"}, got)
+}
+
+func TestIcebergRestMetaStore_GetDatabaseInfo(t *testing.T) {
+	r, catalog := newRestMetaStore(t)
+	got, err := r.GetDatabaseInfo("pls")
+	require.NoError(t, err)
+	require.Equal(t, model.Database{
+		Name:        "pls",
+		Description: "pls namespace",
+		Location:    "s3://bucket/pls",
+		Parameters:  map[string]string{"team": "data"},
+	}, got)
+
+	_, err = r.GetDatabaseInfo("lake.raw")
+	require.NoError(t, err)
+	require.Equal(t, "GET /v1/warehouse/namespaces/lake%1Fraw?", catalog.requests[len(catalog.requests)-1])
+
+	_, err = r.GetDatabaseInfo("missing")
+	require.Error(t, err)
+	require.True(t, isIcebergRestNotFound(err))
+}
+
+func TestIcebergRestMetaStore_CreateDatabase(t *testing.T) {
+	r, catalog := newRestMetaStore(t)
+	err := r.CreateDatabase(model.Database{Name: "new", Description: "new namespace", Location: "s3a://bucket/new", Parameters: map[string]string{"team": "data"}})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"location": "s3://bucket/new", "comment": "new namespace", "team": "data"}, catalog.namespaces["new"])
+}
+
+func TestIcebergRestMetaStore_DropDatabase(t *testing.T) {
+	tests := []struct {
+		name    string
+		dbName  string
+		cascade bool
+		wantErr bool
+	}{
+		{name: "shouldDropEmpty", dbName: "empty"},
+		{name: "shouldErrorWhenNotEmpty", dbName: "pls", wantErr: true},
+		{name: "shouldDropTablesWithCascade", dbName: "pls", cascade: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, catalog := newRestMetaStore(t)
+			err := r.DropDatabase(tt.dbName, tt.cascade)
+			if tt.wantErr {
+				require.Error(t, err)
+				require.Contains(t, catalog.namespaces, tt.dbName)
+				return
+			}
+			require.NoError(t, err)
+			require.NotContains(t, catalog.namespaces, tt.dbName)
+			require.Empty(t, catalog.purged)
+		})
+	}
+}
+
+func TestIcebergRestMetaStore_GetTables(t *testing.T) {
+	r, _ := newRestMetaStore(t)
+	got, err := r.GetTables("pls")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"events", "clicks"}, got)
+}
+
+func TestIcebergRestMetaStore_GetTableInfo(t *testing.T) {
+	r, _ := newRestMetaStore(t)
+	got, err := r.GetTableInfo("pls", "events")
+	require.NoError(t, err)
+	require.Equal(t, model.TableInfo{
+		Name: "events",
+		Columns: []model.Column{
+			{Name: "id", Type: model.ColumnType{SqlType: model.BIGINT}},
+			{Name: "payload", Type: model.ColumnType{SqlType: model.STRING}, Comment: "raw event"},
+		},
+		MetadataLocation: "s3://bucket/pls/events/metadata/00003-aaaa.metadata.json",
+		Format:           model.ICEBERG,
+		Parameters:       map[string]string{"comment": "events table"},
+		Description:      "events table",
+	}, got)
+}
+
+func TestIcebergRestMetaStore_CreateTable(t *testing.T) {
+	tests := []struct {
+		name         string
+		table        model.TableInfo
+		wantLocation string
+		wantErr      bool
+	}{
+		{
+			name:         "shouldRegisterOnMetadataFile",
+			table:        model.TableInfo{Name: "new", Format: model.ICEBERG, MetadataLocation: "s3a://bucket/pls/new/metadata/00002-cccc.metadata.json"},
+			wantLocation: "s3://bucket/pls/new/metadata/00002-cccc.metadata.json",
+		},
+		{
+			name:    "shouldErrorWithTableRoot",
+			table:   model.TableInfo{Name: "new", Format: model.ICEBERG, MetadataLocation: "s3://bucket/pls/new"},
+			wantErr: true,
+		},
+		{
+			name:    "shouldErrorWhenNotIceberg",
+			table:   model.TableInfo{Name: "new", Format: model.PARQUET, MetadataLocation: "s3://bucket/pls/new"},
+			wantErr: true,
+		},
+		{
+			name:    "shouldErrorWhenExisting",
+			table:   model.TableInfo{Name: "events", Format: model.ICEBERG, MetadataLocation: "s3://bucket/pls/events/metadata/00004-dddd.metadata.json"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, catalog := newRestMetaStore(t)
+			err := r.CreateTable("pls", tt.table)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantLocation, catalog.tables["pls"][tt.table.Name])
+		})
+	}
+}
+
+func TestIcebergRestMetaStore_AlterTable(t *testing.T) {
+	r, catalog := newRestMetaStore(t)
+	location := "s3://bucket/pls/events/metadata/00004-dddd.metadata.json"
+	err := r.AlterTable("pls", model.TableInfo{Name: "events", Format: model.ICEBERG, MetadataLocation: location})
+	require.NoError(t, err)
+	require.Equal(t, location, catalog.tables["pls"]["events"])
+	require.Empty(t, catalog.purged)
+
+	requests := len(catalog.requests)
+	err = r.AlterTable("pls", model.TableInfo{Name: "events", Format: model.ICEBERG, MetadataLocation: location})
+	require.NoError(t, err)
+	require.Len(t, catalog.requests, requests+1)
+}
+
+func TestIcebergRestMetaStore_AlterTableRestoresOnRegisterError(t *testing.T) {
+	r, catalog := newRestMetaStore(t)
+	current := catalog.tables["pls"]["events"]
+	location := "s3://bucket/pls/events/metadata/00004-dddd.metadata.json"
+	catalog.unreadable = map[string]bool{location: true}
+	err := r.AlterTable("pls", model.TableInfo{Name: "events", Format: model.ICEBERG, MetadataLocation: location})
+	require.Error(t, err)
+	require.Equal(t, current, catalog.tables["pls"]["events"])
+
+	catalog.unreadable[current] = true
+	err = r.AlterTable("pls", model.TableInfo{Name: "events", Format: model.ICEBERG, MetadataLocation: location})
+	require.ErrorContains(t, err, "could not be registered again on "+current)
+	require.NotContains(t, catalog.tables["pls"], "events")
+}
+
+func TestIcebergRestMetaStore_DropTable(t *testing.T) {
+	tests := []struct {
+		name       string
+		tableName  string
+		deleteData bool
+		wantPurged []string
+	}{
+		{name: "shouldDropKeepingData", tableName: "events"},
+		{name: "shouldDropPurgingData", tableName: "events", deleteData: true, wantPurged: []string{"events"}},
+		{name: "shouldIgnoreMissing", tableName: "missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, catalog := newRestMetaStore(t)
+			require.NoError(t, r.DropTable("pls", tt.tableName, tt.deleteData))
+			require.NotContains(t, catalog.tables["pls"], tt.tableName)
+			require.Equal(t, tt.wantPurged, catalog.purged)
+		})
+	}
+}
+
+func TestIcebergRestMetaStore_Unauthorized(t *testing.T) {
+	r, _ := newRestMetaStore(t)
+	r.token = "wrong"
+	_, err := r.GetDatabases()
+	require.EqualError(t, err, "iceberg rest catalog: 401 NotAuthorizedException: invalid token")
+}
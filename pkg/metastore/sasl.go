@@ -0,0 +1,280 @@
+package metastore
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"github.com/apache/thrift/lib/go/thrift"
+	krbClient "github.com/jcmturner/gokrb5/v8/client"
+	krbConfig "github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/crypto"
+	"github.com/jcmturner/gokrb5/v8/gssapi"
+	"github.com/jcmturner/gokrb5/v8/iana/flags"
+	"github.com/jcmturner/gokrb5/v8/iana/keyusage"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/messages"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+	"github.com/jcmturner/gokrb5/v8/types"
+	"io"
+	"strings"
+)
+
+// thrift SASL negotiation statuses
+const (
+	saslStart    byte = 1
+	saslOk       byte = 2
+	saslBad      byte = 3
+	saslError    byte = 4
+	saslComplete byte = 5
+)
+
+const saslMaxMessageSize = 1024 * 1024
+
+// SaslMechanism authenticates hive connections, with a new SaslClient for each of them.
+type SaslMechanism interface {
+	Name() string
+	NewClient() SaslClient
+}
+
+// SaslClient is the client side of a SASL negotiation: Start gives the initial response
+// and Step answers the server challenges until it completes.
+type SaslClient interface {
+	Start() ([]byte, error)
+	Step(challenge []byte) ([]byte, error)
+}
+
+// saslTransport authenticates the underlying transport on Open, then frames the messages as the
+// thrift SASL transport does when no security layer is negotiated.
+type saslTransport struct {
+	*thrift.TFramedTransport
+	transport thrift.TTransport
+	mechanism SaslMechanism
+}
+
+func newSaslTransport(transport thrift.TTransport, mechanism SaslMechanism, conf *thrift.TConfiguration) *saslTransport {
+	return &saslTransport{TFramedTransport: thrift.NewTFramedTransportConf(transport, conf), transport: transport, mechanism: mechanism}
+}
+
+func (s *saslTransport) Open() error {
+	if err := s.transport.Open(); err != nil {
+		return err
+	}
+	if err := s.negotiate(); err != nil {
+		s.transport.Close()
+		return fmt.Errorf("sasl %s negotiation failed: %w", s.mechanism.Name(), err)
+	}
+	return nil
+}
+
+func (s *saslTransport) negotiate() error {
+	client := s.mechanism.NewClient()
+	response, err := client.Start()
+	if err != nil {
+		return err
+	}
+	if err := s.send(saslStart, []byte(s.mechanism.Name())); err != nil {
+		return err
+	}
+	if err := s.send(saslOk, response); err != nil {
+		return err
+	}
+	for {
+		status, challenge, err := s.receive()
+		if err != nil {
+			return err
+		}
+		switch status {
+		case saslComplete:
+			return nil
+		case saslOk:
+			response, err := client.Step(challenge)
+			if err != nil {
+				return err
+			}
+			if err := s.send(saslOk, response); err != nil {
+				return err
+			}
+		case saslBad, saslError:
+			return fmt.Errorf("server refused authentication: %s", challenge)
+		default:
+			return fmt.Errorf("unexpected sasl status %d", status)
+		}
+	}
+}
+
+func (s *saslTransport) send(status byte, payload []byte) error {
+	header := make([]byte, 5)
+	header[0] = status
+	binary.BigEndian.PutUint32(header[1:], uint32(len(payload)))
+	if _, err := s.transport.Write(append(header, payload...)); err != nil {
+		return err
+	}
+	return s.transport.Flush(context.Background())
+}
+
+func (s *saslTransport) receive() (byte, []byte, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(s.transport, header); err != nil {
+		return 0, nil, err
+	}
+	length := binary.BigEndian.Uint32(header[1:])
+	if length > saslMaxMessageSize {
+		return 0, nil, fmt.Errorf("sasl message of %d bytes exceeds the maximum size", length)
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(s.transport, payload); err != nil {
+		return 0, nil, err
+	}
+	return header[0], payload, nil
+}
+
+// SaslPlain authenticates with username and password, sent in clear: use it over TLS.
+type SaslPlain struct {
+	username string
+	password string
+}
+
+func NewSaslPlain(username string, password string) *SaslPlain {
+	return &SaslPlain{username: username, password: password}
+}
+
+func (p *SaslPlain) Name() string {
+	return "PLAIN"
+}
+
+func (p *SaslPlain) NewClient() SaslClient {
+	return p
+}
+
+func (p *SaslPlain) Start() ([]byte, error) {
+	return []byte("\x00" + p.username + "\x00" + p.password), nil
+}
+
+func (p *SaslPlain) Step(_ []byte) ([]byte, error) {
+	return nil, fmt.Errorf("unexpected challenge for PLAIN")
+}
+
+// SaslGssapi authenticates with kerberos to the service principal, e.g. hive/metastore.example.com.
+// Only the RFC 4121 encryption types (aes) are supported.
+type SaslGssapi struct {
+	client  *krbClient.Client
+	service string
+}
+
+func NewSaslGssapi(client *krbClient.Client, servicePrincipal string) *SaslGssapi {
+	service, _, _ := strings.Cut(servicePrincipal, "@")
+	return &SaslGssapi{client: client, service: service}
+}
+
+// NewKerberosClient logs in as principal, e.g. metaman@EXAMPLE.COM, with the keys of keytabPath.
+func NewKerberosClient(principal string, keytabPath string, krb5ConfPath string) (*krbClient.Client, error) {
+	username, realm, found := strings.Cut(principal, "@")
+	if !found {
+		return nil, fmt.Errorf("kerberos principal %s has no realm", principal)
+	}
+	kt, err := keytab.Load(keytabPath)
+	if err != nil {
+		return nil, fmt.Errorf("loading keytab %s: %w", keytabPath, err)
+	}
+	conf, err := krbConfig.Load(krb5ConfPath)
+	if err != nil {
+		return nil, fmt.Errorf("loading kerberos configuration %s: %w", krb5ConfPath, err)
+	}
+	return krbClient.NewWithKeytab(username, realm, kt, conf, krbClient.DisablePAFXFAST(true)), nil
+}
+
+func (g *SaslGssapi) Name() string {
+	return "GSSAPI"
+}
+
+func (g *SaslGssapi) NewClient() SaslClient {
+	return &gssapiClient{client: g.client, service: g.service}
+}
+
+type gssapiClient struct {
+	client         *krbClient.Client
+	service        string
+	key            types.EncryptionKey
+	acceptorSubkey bool
+	established    bool
+}
+
+func (g *gssapiClient) Start() ([]byte, error) {
+	if err := g.client.AffirmLogin(); err != nil {
+		return nil, err
+	}
+	ticket, key, err := g.client.GetServiceTicket(g.service)
+	if err != nil {
+		return nil, err
+	}
+	g.key = key
+	token, err := spnego.NewKRB5TokenAPREQ(g.client, ticket, key,
+		[]int{gssapi.ContextFlagInteg, gssapi.ContextFlagConf, gssapi.ContextFlagMutual},
+		[]int{flags.APOptionMutualRequired})
+	if err != nil {
+		return nil, err
+	}
+	return token.Marshal()
+}
+
+func (g *gssapiClient) Step(challenge []byte) ([]byte, error) {
+	if !g.established {
+		g.established = true
+		return nil, g.acceptContext(challenge)
+	}
+	return gssapiSecurityLayer(challenge, g.key, g.acceptorSubkey)
+}
+
+// acceptContext reads the AP-REP of the mutual authentication, taking the acceptor subkey if any.
+func (g *gssapiClient) acceptContext(challenge []byte) error {
+	var token spnego.KRB5Token
+	if err := token.Unmarshal(challenge); err != nil {
+		return err
+	}
+	if token.IsKRBError() {
+		return fmt.Errorf("kerberos error: %s", token.KRBError.Error())
+	}
+	if !token.IsAPRep() {
+		return fmt.Errorf("expected an AP-REP token")
+	}
+	decrypted, err := crypto.DecryptEncPart(token.APRep.EncPart, g.key, keyusage.AP_REP_ENCPART)
+	if err != nil {
+		return err
+	}
+	var part messages.EncAPRepPart
+	if err := part.Unmarshal(decrypted); err != nil {
+		return err
+	}
+	if part.Subkey.KeyType != 0 {
+		g.key = part.Subkey
+		g.acceptorSubkey = true
+	}
+	return nil
+}
+
+// gssapiSecurityLayer verifies the RFC 4752 security layer offer wrapped in challenge
+// and answers selecting no security layer.
+func gssapiSecurityLayer(challenge []byte, key types.EncryptionKey, acceptorSubkey bool) ([]byte, error) {
+	var offer gssapi.WrapToken
+	if err := offer.Unmarshal(challenge, true); err != nil {
+		return nil, err
+	}
+	if _, err := offer.Verify(key, keyusage.GSSAPI_ACCEPTOR_SEAL); err != nil {
+		return nil, err
+	}
+	if len(offer.Payload) != 4 || offer.Payload[0]&0x01 == 0 {
+		return nil, fmt.Errorf("server requires a sasl security layer")
+	}
+	encType, err := crypto.GetEtype(key.KeyType)
+	if err != nil {
+		return nil, err
+	}
+	answer := gssapi.WrapToken{EC: uint16(encType.GetHMACBitLength() / 8), Payload: []byte{0x01, 0, 0, 0}}
+	if acceptorSubkey {
+		answer.Flags = 0x04
+	}
+	if err := answer.SetCheckSum(key, keyusage.GSSAPI_INITIATOR_SEAL); err != nil {
+		return nil, err
+	}
+	return answer.Marshal()
+}
@@ -11,9 +11,28 @@ type DropApiRequest struct {
 }
 
 type SyncApiRequest struct {
-	Source string   `json:"source"`
-	Target string   `json:"target"`
-	DbName string   `json:"db"`
-	Tables []string `json:"tables"`
-	Delete bool     `json:"delete"`
+	Source          string   `json:"source"`
+	Target          string   `json:"target"`
+	DbName          string   `json:"db"`
+	Tables          []string `json:"tables"`
+	Delete          bool     `json:"delete"`
+	CreateDatabase  bool     `json:"create_database"`
+	AllDatabases    bool     `json:"all_databases"`
+	DatabasePattern string   `json:"database_pattern"`
+}
+
+type SyncResult struct {
+	Db    string `json:"db"`
+	Error string `json:"error,omitempty"`
+}
+
+type CreateDatabaseApiRequest struct {
+	Metastores []string   `json:"metastores"`
+	Databases  []Database `json:"databases"`
+}
+
+type DropDatabaseApiRequest struct {
+	Metastore string   `json:"metastore"`
+	Databases []string `json:"databases"`
+	Cascade   bool     `json:"cascade"`
 }
@@ -0,0 +1,434 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type ColumnType struct {
+	SqlType    SqlType       `json:"sql_type" yaml:"sql_type,omitempty"`
+	Length     int           `json:"length" yaml:"length,omitempty"`
+	Precision  int           `json:"precision,omitempty" yaml:"precision,omitempty"`
+	Scale      int           `json:"scale,omitempty" yaml:"scale,omitempty"`
+	Element    *ColumnType   `json:"element,omitempty" yaml:"element,omitempty"`
+	Key        *ColumnType   `json:"key,omitempty" yaml:"key,omitempty"`
+	Value      *ColumnType   `json:"value,omitempty" yaml:"value,omitempty"`
+	Fields     []StructField `json:"fields,omitempty" yaml:"fields,omitempty"`
+	Types      []ColumnType  `json:"types,omitempty" yaml:"types,omitempty"`
+	Definition string        `json:"definition,omitempty" yaml:"definition,omitempty"`
+}
+
+type StructField struct {
+	Name string     `json:"name" yaml:"name,omitempty"`
+	Type ColumnType `json:"type" yaml:"type,omitempty"`
+}
+
+type SqlType string
+
+const (
+	VARCHAR             SqlType = "varchar"
+	CHAR                        = "char"
+	STRING                      = "string"
+	TINYINT                     = "tinyint"
+	SMALLINT                    = "smallint"
+	INTEGER                     = "int"
+	BIGINT                      = "bigint"
+	FLOAT                       = "float"
+	DOUBLE                      = "double"
+	DECIMAL                     = "decimal"
+	DATE                        = "date"
+	TIMESTAMP                   = "timestamp"
+	BOOLEAN                     = "boolean"
+	BINARY                      = "binary"
+	INTERVAL_YEAR_MONTH         = "interval_year_month"
+	INTERVAL_DAY_TIME           = "interval_day_time"
+	ARRAY                       = "array"
+	MAP                         = "map"
+	STRUCT                      = "struct"
+	UNIONTYPE                   = "uniontype"
+	RAW                         = "raw"
+)
+
+const (
+	maxVarcharLength = 65535
+	maxCharLength    = 255
+	maxDecimalDigits = 38
+	integerTypeAlias = "integer"
+	numericTypeAlias = "numeric"
+)
+
+var primitiveTypes = map[SqlType]bool{
+	STRING:              true,
+	TINYINT:             true,
+	SMALLINT:            true,
+	INTEGER:             true,
+	BIGINT:              true,
+	FLOAT:               true,
+	DOUBLE:              true,
+	DATE:                true,
+	TIMESTAMP:           true,
+	BOOLEAN:             true,
+	BINARY:              true,
+	INTERVAL_YEAR_MONTH: true,
+	INTERVAL_DAY_TIME:   true,
+}
+
+// MapColumnType parses a hive type definition, e.g. "map<string,array<decimal(10,2)>>".
+func MapColumnType(t string) (ColumnType, error) {
+	p := &typeParser{input: t}
+	columnType, err := p.parseType()
+	if err == nil {
+		p.skipSpaces()
+		if p.pos < len(p.input) {
+			err = fmt.Errorf("unexpected '%s' at position %d", p.input[p.pos:], p.pos)
+		}
+	}
+	if err == nil {
+		err = columnType.Validate()
+	}
+	if err != nil {
+		return ColumnType{}, fmt.Errorf("invalid column type '%s': %s", t, err.Error())
+	}
+	return columnType, nil
+}
+
+// ReadColumnType parses a type read from a metastore, the definitions MapColumnType rejects, e.g.
+// "timestamp with local time zone", are kept verbatim in RAW types so that the table can still be read and synced.
+func ReadColumnType(t string) ColumnType {
+	columnType, err := MapColumnType(t)
+	if err != nil {
+		return ColumnType{SqlType: RAW, Definition: t}
+	}
+	return columnType
+}
+
+// UnmapColumnType prints the hive type definition of t.
+func UnmapColumnType(t ColumnType) string {
+	switch t.SqlType {
+	case VARCHAR, CHAR:
+		return fmt.Sprintf("%s(%d)", t.SqlType, t.Length)
+	case DECIMAL:
+		if t.Precision == 0 {
+			return DECIMAL
+		}
+		return fmt.Sprintf("%s(%d,%d)", DECIMAL, t.Precision, t.Scale)
+	case ARRAY:
+		return fmt.Sprintf("%s<%s>", ARRAY, unmapColumnTypePtr(t.Element))
+	case MAP:
+		return fmt.Sprintf("%s<%s,%s>", MAP, unmapColumnTypePtr(t.Key), unmapColumnTypePtr(t.Value))
+	case STRUCT:
+		fields := make([]string, len(t.Fields))
+		for i, field := range t.Fields {
+			fields[i] = fmt.Sprintf("%s:%s", quoteFieldName(field.Name), UnmapColumnType(field.Type))
+		}
+		return fmt.Sprintf("%s<%s>", STRUCT, strings.Join(fields, ","))
+	case UNIONTYPE:
+		types := make([]string, len(t.Types))
+		for i, unionType := range t.Types {
+			types[i] = UnmapColumnType(unionType)
+		}
+		return fmt.Sprintf("%s<%s>", UNIONTYPE, strings.Join(types, ","))
+	case RAW:
+		return t.Definition
+	default:
+		return string(t.SqlType)
+	}
+}
+
+// quoteFieldName quotes with backticks the struct field names that are not plain identifiers.
+func quoteFieldName(name string) string {
+	if plainIdentifier.MatchString(name) {
+		return name
+	}
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func unmapColumnTypePtr(t *ColumnType) string {
+	if t == nil {
+		return ""
+	}
+	return UnmapColumnType(*t)
+}
+
+// Validate checks that t describes a type the metastores can store.
+func (t ColumnType) Validate() error {
+	switch t.SqlType {
+	case VARCHAR:
+		if t.Length < 1 || t.Length > maxVarcharLength {
+			return fmt.Errorf("varchar length must be between 1 and %d, got %d", maxVarcharLength, t.Length)
+		}
+	case CHAR:
+		if t.Length < 1 || t.Length > maxCharLength {
+			return fmt.Errorf("char length must be between 1 and %d, got %d", maxCharLength, t.Length)
+		}
+	case DECIMAL:
+		if t.Precision == 0 && t.Scale == 0 {
+			return nil
+		}
+		if t.Precision < 1 || t.Precision > maxDecimalDigits {
+			return fmt.Errorf("decimal precision must be between 1 and %d, got %d", maxDecimalDigits, t.Precision)
+		}
+		if t.Scale < 0 || t.Scale > t.Precision {
+			return fmt.Errorf("decimal scale must be between 0 and %d, got %d", t.Precision, t.Scale)
+		}
+	case ARRAY:
+		if t.Element == nil {
+			return fmt.Errorf("array element type is missing")
+		}
+		return t.Element.Validate()
+	case MAP:
+		if t.Key == nil || t.Value == nil {
+			return fmt.Errorf("map key or value type is missing")
+		}
+		if !primitiveTypes[t.Key.SqlType] && t.Key.SqlType != VARCHAR && t.Key.SqlType != CHAR && t.Key.SqlType != DECIMAL {
+			return fmt.Errorf("map key must be a primitive type, got %s", t.Key.SqlType)
+		}
+		if err := t.Key.Validate(); err != nil {
+			return err
+		}
+		return t.Value.Validate()
+	case STRUCT:
+		if len(t.Fields) == 0 {
+			return fmt.Errorf("struct must have at least one field")
+		}
+		for _, field := range t.Fields {
+			if field.Name == "" {
+				return fmt.Errorf("struct field name is missing")
+			}
+			if err := field.Type.Validate(); err != nil {
+				return err
+			}
+		}
+	case RAW:
+		if strings.TrimSpace(t.Definition) == "" {
+			return fmt.Errorf("raw type definition is missing")
+		}
+	case UNIONTYPE:
+		if len(t.Types) == 0 {
+			return fmt.Errorf("uniontype must have at least one type")
+		}
+		for _, unionType := range t.Types {
+			if err := unionType.Validate(); err != nil {
+				return err
+			}
+		}
+	default:
+		if !primitiveTypes[t.SqlType] {
+			return fmt.Errorf("unsupported type '%s'", t.SqlType)
+		}
+	}
+	return nil
+}
+
+var plainIdentifier = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+type typeParser struct {
+	input string
+	pos   int
+}
+
+func (p *typeParser) parseType() (ColumnType, error) {
+	name := strings.ToLower(p.parseIdentifier())
+	if name == "" {
+		return ColumnType{}, p.errorf("type name expected")
+	}
+	switch name {
+	case integerTypeAlias:
+		return ColumnType{SqlType: INTEGER}, nil
+	case string(VARCHAR), CHAR:
+		length, err := p.parseArguments(1, 1)
+		if err != nil {
+			return ColumnType{}, err
+		}
+		return ColumnType{SqlType: SqlType(name), Length: length[0]}, nil
+	case DECIMAL, numericTypeAlias:
+		if !p.peek('(') {
+			return ColumnType{SqlType: DECIMAL}, nil
+		}
+		args, err := p.parseArguments(1, 2)
+		if err != nil {
+			return ColumnType{}, err
+		}
+		columnType := ColumnType{SqlType: DECIMAL, Precision: args[0]}
+		if len(args) == 2 {
+			columnType.Scale = args[1]
+		}
+		return columnType, nil
+	case ARRAY:
+		types, err := p.parseTypeList(1, 1)
+		if err != nil {
+			return ColumnType{}, err
+		}
+		return ColumnType{SqlType: ARRAY, Element: &types[0]}, nil
+	case MAP:
+		types, err := p.parseTypeList(2, 2)
+		if err != nil {
+			return ColumnType{}, err
+		}
+		return ColumnType{SqlType: MAP, Key: &types[0], Value: &types[1]}, nil
+	case UNIONTYPE:
+		types, err := p.parseTypeList(1, -1)
+		if err != nil {
+			return ColumnType{}, err
+		}
+		return ColumnType{SqlType: UNIONTYPE, Types: types}, nil
+	case STRUCT:
+		fields, err := p.parseStructFields()
+		if err != nil {
+			return ColumnType{}, err
+		}
+		return ColumnType{SqlType: STRUCT, Fields: fields}, nil
+	default:
+		return ColumnType{SqlType: SqlType(name)}, nil
+	}
+}
+
+func (p *typeParser) parseArguments(minArgs, maxArgs int) ([]int, error) {
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	args := make([]int, 0, maxArgs)
+	for {
+		p.skipSpaces()
+		start := p.pos
+		for p.pos < len(p.input) && p.input[p.pos] >= '0' && p.input[p.pos] <= '9' {
+			p.pos++
+		}
+		arg, err := strconv.Atoi(p.input[start:p.pos])
+		if err != nil {
+			return nil, p.errorf("number expected")
+		}
+		args = append(args, arg)
+		if !p.peek(',') {
+			break
+		}
+		p.pos++
+	}
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+	if len(args) < minArgs || len(args) > maxArgs {
+		return nil, p.errorf("expected between %d and %d arguments, got %d", minArgs, maxArgs, len(args))
+	}
+	return args, nil
+}
+
+func (p *typeParser) parseTypeList(minTypes, maxTypes int) ([]ColumnType, error) {
+	if err := p.expect('<'); err != nil {
+		return nil, err
+	}
+	types := make([]ColumnType, 0)
+	for {
+		p.skipSpaces()
+		columnType, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, columnType)
+		if !p.peek(',') {
+			break
+		}
+		p.pos++
+	}
+	if err := p.expect('>'); err != nil {
+		return nil, err
+	}
+	if len(types) < minTypes || (maxTypes > 0 && len(types) > maxTypes) {
+		return nil, p.errorf("unexpected number of type arguments: %d", len(types))
+	}
+	return types, nil
+}
+
+func (p *typeParser) parseStructFields() ([]StructField, error) {
+	if err := p.expect('<'); err != nil {
+		return nil, err
+	}
+	fields := make([]StructField, 0)
+	for {
+		p.skipSpaces()
+		name, err := p.parseFieldName()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(':'); err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		columnType, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, StructField{Name: name, Type: columnType})
+		if !p.peek(',') {
+			break
+		}
+		p.pos++
+	}
+	if err := p.expect('>'); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func (p *typeParser) parseFieldName() (string, error) {
+	if p.peek('`') {
+		p.pos++
+		var name strings.Builder
+		for {
+			end := strings.IndexByte(p.input[p.pos:], '`')
+			if end < 0 {
+				return "", p.errorf("unterminated quoted field name")
+			}
+			name.WriteString(p.input[p.pos : p.pos+end])
+			p.pos += end + 1
+			if p.pos >= len(p.input) || p.input[p.pos] != '`' {
+				return name.String(), nil
+			}
+			name.WriteByte('`')
+			p.pos++
+		}
+	}
+	name := p.parseIdentifier()
+	if name == "" {
+		return "", p.errorf("field name expected")
+	}
+	return name, nil
+}
+
+func (p *typeParser) parseIdentifier() string {
+	p.skipSpaces()
+	start := p.pos
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		if !(c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')) {
+			break
+		}
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+func (p *typeParser) peek(c byte) bool {
+	p.skipSpaces()
+	return p.pos < len(p.input) && p.input[p.pos] == c
+}
+
+func (p *typeParser) expect(c byte) error {
+	if !p.peek(c) {
+		return p.errorf("'%c' expected", c)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *typeParser) skipSpaces() {
+	for p.pos < len(p.input) && strings.IndexByte(" \t\n", p.input[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *typeParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%s at position %d", fmt.Sprintf(format, args...), p.pos)
+}
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMapColumnType_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		t    string
+		want string
+	}{
+		{name: "shouldPrimitive", t: "bigint", want: "bigint"},
+		{name: "shouldVarchar", t: "varchar(255)", want: "varchar(255)"},
+		{name: "shouldChar", t: "CHAR(10)", want: "char(10)"},
+		{name: "shouldDecimal", t: "decimal(10, 2)", want: "decimal(10,2)"},
+		{name: "shouldBareDecimal", t: "decimal", want: "decimal"},
+		{name: "shouldIntegerAlias", t: "integer", want: "int"},
+		{name: "shouldArray", t: "array<string>", want: "array<string>"},
+		{name: "shouldMap", t: "map<string,array<decimal(5,1)>>", want: "map<string,array<decimal(5,1)>>"},
+		{name: "shouldStruct", t: "struct<id:bigint, tags:array<varchar(20)>, attrs:map<string,int>>", want: "struct<id:bigint,tags:array<varchar(20)>,attrs:map<string,int>>"},
+		{name: "shouldUnion", t: "uniontype<int,string,struct<a:double>>", want: "uniontype<int,string,struct<a:double>>"},
+		{name: "shouldQuotedFieldName", t: "struct<`a b`:int,`c`:string>", want: "struct<`a b`:int,c:string>"},
+		{name: "shouldEscapedBacktick", t: "struct<`a``b`:int>", want: "struct<`a``b`:int>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MapColumnType(tt.t)
+			require.NoError(t, err)
+			printed := UnmapColumnType(got)
+			require.Equal(t, tt.want, printed)
+			reparsed, err := MapColumnType(printed)
+			require.NoError(t, err)
+			require.Equal(t, got, reparsed)
+		})
+	}
+}
+
+func TestReadColumnType(t *testing.T) {
+	tests := []struct {
+		name string
+		t    string
+		want ColumnType
+	}{
+		{name: "shouldParseKnownType", t: "array<int>", want: ColumnType{SqlType: ARRAY, Element: &ColumnType{SqlType: INTEGER}}},
+		{name: "shouldKeepTimestampWithLocalTimeZone", t: "timestamp with local time zone", want: ColumnType{SqlType: RAW, Definition: "timestamp with local time zone"}},
+		{name: "shouldKeepVoid", t: "void", want: ColumnType{SqlType: RAW, Definition: "void"}},
+		{name: "shouldKeepNestedUnknownType", t: "struct<a:void>", want: ColumnType{SqlType: RAW, Definition: "struct<a:void>"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ReadColumnType(tt.t)
+			require.Equal(t, tt.want, got)
+			require.NoError(t, got.Validate())
+			require.Equal(t, tt.t, UnmapColumnType(got))
+		})
+	}
+}
+
+func TestMapColumnType_Invalid(t *testing.T) {
+	tests := []string{
+		"",
+		"varchar(0)",
+		"varchar(70000)",
+		"char(300)",
+		"decimal(40,2)",
+		"decimal(5,6)",
+		"map<array<int>,string>",
+		"array<int",
+		"struct<a int>",
+		"geometry",
+		"int extra",
+		"raw",
+	}
+	for _, tt := range tests {
+		t.Run(tt, func(t *testing.T) {
+			_, err := MapColumnType(tt)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package model
+
+type Database struct {
+	Name        string            `json:"name" yaml:"name,omitempty"`
+	Description string            `json:"description" yaml:"description,omitempty"`
+	Location    string            `json:"location" yaml:"location,omitempty"`
+	Parameters  map[string]string `json:"parameters" yaml:"parameters,omitempty"`
+}
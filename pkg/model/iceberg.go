@@ -0,0 +1,30 @@
+package model
+
+import (
+	"path"
+	"regexp"
+	"strconv"
+)
+
+const (
+	IcebergMetadataLocation         = "metadata_location"
+	IcebergPreviousMetadataLocation = "previous_metadata_location"
+)
+
+// iceberg metadata files are named either v<version>.metadata.json (hadoop tables)
+// or <version>-<uuid>.metadata.json (catalog tables), gzip compressed ones
+// ending with .gz.metadata.json or, for older writers, .metadata.json.gz.
+var icebergMetadataVersion = regexp.MustCompile(`^v?(\d+)(-.*)?(\.gz)?\.metadata\.json(\.gz)?$`)
+
+// IcebergMetadataVersion returns the version of an iceberg metadata file from its location.
+func IcebergMetadataVersion(location string) (int, bool) {
+	match := icebergMetadataVersion.FindStringSubmatch(path.Base(location))
+	if match == nil {
+		return 0, false
+	}
+	version, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, false
+	}
+	return version, true
+}
@@ -1,10 +1,6 @@
 package model
 
-import (
-	"fmt"
-	"strconv"
-	"strings"
-)
+import "strings"
 
 type SerDeInfo struct {
 	SerializationLib string
@@ -16,9 +12,88 @@ type TableFormat string
 const (
 	PARQUET        TableFormat = "parquet"
 	ICEBERG                    = "iceberg"
+	DELTA                      = "delta"
+	DELTA_SYMLINK              = "delta_symlink"
+	HUDI_COW                   = "hudi_cow"
+	HUDI_MOR                   = "hudi_mor"
+	ORC                        = "orc"
+	AVRO                       = "avro"
+	CSV                        = "csv"
+	TEXTFILE                   = "textfile"
+	JSON                       = "json"
+	UNKNOWN                    = "unknown"
 	EXTERNAL_TABLE             = "EXTERNAL_TABLE"
 )
 
+const (
+	SparkProviderParameter   = "spark.sql.sources.provider"
+	DeltaSymlinkManifestPath = "_symlink_format_manifest"
+	symlinkInputFormat       = "org.apache.hadoop.hive.ql.io.SymlinkTextInputFormat"
+	HudiReadOptimizedSuffix  = "_ro"
+	HudiReadOptimizedSerde   = "hoodie.query.as.ro.table"
+	hudiInputFormat          = "org.apache.hudi.hadoop.HoodieParquetInputFormat"
+	hudiRealtimeInputFormat  = "org.apache.hudi.hadoop.realtime.HoodieParquetRealtimeInputFormat"
+	textInputFormat          = "org.apache.hadoop.mapred.TextInputFormat"
+	textOutputFormat         = "org.apache.hadoop.hive.ql.io.HiveIgnoreKeyTextOutputFormat"
+	parquetSerde             = "org.apache.hadoop.hive.ql.io.parquet.serde.ParquetHiveSerDe"
+	lazySimpleSerde          = "org.apache.hadoop.hive.serde2.lazy.LazySimpleSerDe"
+	openCSVSerde             = "org.apache.hadoop.hive.serde2.OpenCSVSerde"
+	openxJsonSerde           = "org.openx.data.jsonserde.JsonSerDe"
+)
+
+// FromTable detects the table format from its input format, location, table parameters and serde,
+// needed for formats such as delta that spark registers with a placeholder input format,
+// delta symlink tables that share their input format with any other symlink table,
+// hudi merge-on-read whose read optimized table shares the copy-on-write input format
+// or text tables whose content depends on the serde.
+func FromTable(input string, location string, parameters map[string]string, serde *SerDeInfo) TableFormat {
+	var serdeLib string
+	var serdeParameters map[string]string
+	if serde != nil {
+		serdeLib = serde.SerializationLib
+		serdeParameters = serde.Parameters
+	}
+	if strings.EqualFold(parameters["table_type"], ICEBERG) {
+		return ICEBERG
+	}
+	switch input {
+	case "org.apache.iceberg.mr.hive.HiveIcebergInputFormat":
+		return ICEBERG
+	case symlinkInputFormat:
+		if isDeltaSymlinkManifest(location) || strings.EqualFold(parameters[SparkProviderParameter], string(DELTA)) {
+			return DELTA_SYMLINK
+		}
+		return UNKNOWN
+	case hudiRealtimeInputFormat, "com.uber.hoodie.hadoop.realtime.HoodieRealtimeInputFormat":
+		return HUDI_MOR
+	case hudiInputFormat, "com.uber.hoodie.hadoop.HoodieInputFormat":
+		if serdeParameters[HudiReadOptimizedSerde] == "true" || strings.EqualFold(parameters["type"], "mor") {
+			return HUDI_MOR
+		}
+		return HUDI_COW
+	case textInputFormat:
+		switch serdeLib {
+		case openCSVSerde:
+			return CSV
+		case openxJsonSerde:
+			return JSON
+		case lazySimpleSerde, "":
+			return TEXTFILE
+		default:
+			return UNKNOWN
+		}
+	}
+	if strings.EqualFold(parameters[SparkProviderParameter], string(DELTA)) {
+		return DELTA
+	}
+	return FromInputOutput(input)
+}
+
+// isDeltaSymlinkManifest tells whether the location is the manifest directory delta generates for symlink tables.
+func isDeltaSymlinkManifest(location string) bool {
+	return strings.HasSuffix(strings.TrimSuffix(location, "/"), "/"+DeltaSymlinkManifestPath)
+}
+
 func FromInputOutput(input string) TableFormat {
 	switch input {
 	case "org.apache.hadoop.hiveFactory.ql.io.parquet.MapredParquetInputFormat":
@@ -29,8 +104,16 @@ func FromInputOutput(input string) TableFormat {
 		fallthrough
 	case "org.apache.hadoop.hive.ql.io.parquet.MapredParquetOutputFormat":
 		return PARQUET
+	case "org.apache.hadoop.hive.ql.io.orc.OrcInputFormat":
+		fallthrough
+	case "org.apache.hadoop.hive.ql.io.orc.OrcOutputFormat":
+		return ORC
+	case "org.apache.hadoop.hive.ql.io.avro.AvroContainerInputFormat":
+		fallthrough
+	case "org.apache.hadoop.hive.ql.io.avro.AvroContainerOutputFormat":
+		return AVRO
 	default:
-		return ICEBERG
+		return UNKNOWN
 	}
 }
 
@@ -38,6 +121,20 @@ func (t TableFormat) InputFormat() string {
 	switch t {
 	case PARQUET:
 		return "org.apache.hadoop.hive.ql.io.parquet.MapredParquetInputFormat"
+	case DELTA:
+		return "org.apache.hadoop.mapred.SequenceFileInputFormat"
+	case DELTA_SYMLINK:
+		return symlinkInputFormat
+	case HUDI_COW:
+		return hudiInputFormat
+	case HUDI_MOR:
+		return hudiRealtimeInputFormat
+	case ORC:
+		return "org.apache.hadoop.hive.ql.io.orc.OrcInputFormat"
+	case AVRO:
+		return "org.apache.hadoop.hive.ql.io.avro.AvroContainerInputFormat"
+	case CSV, TEXTFILE, JSON:
+		return textInputFormat
 	case ICEBERG:
 		return ""
 	default:
@@ -47,8 +144,16 @@ func (t TableFormat) InputFormat() string {
 
 func (t TableFormat) OutputFormat() string {
 	switch t {
-	case PARQUET:
+	case PARQUET, HUDI_COW, HUDI_MOR:
 		return "org.apache.hadoop.hive.ql.io.parquet.MapredParquetOutputFormat"
+	case DELTA:
+		return "org.apache.hadoop.hive.ql.io.HiveSequenceFileOutputFormat"
+	case DELTA_SYMLINK, CSV, TEXTFILE, JSON:
+		return textOutputFormat
+	case ORC:
+		return "org.apache.hadoop.hive.ql.io.orc.OrcOutputFormat"
+	case AVRO:
+		return "org.apache.hadoop.hive.ql.io.avro.AvroContainerOutputFormat"
 	case ICEBERG:
 		return ""
 	default:
@@ -58,7 +163,7 @@ func (t TableFormat) OutputFormat() string {
 
 func (t TableFormat) SerDeInfo() *SerDeInfo {
 	switch t {
-	case PARQUET:
+	case PARQUET, DELTA, DELTA_SYMLINK, HUDI_COW, HUDI_MOR, ORC, AVRO, CSV, TEXTFILE, JSON:
 		return &SerDeInfo{
 			Parameters:       t.SerdeParameters(),
 			SerializationLib: t.SerdeLibrary(),
@@ -72,8 +177,18 @@ func (t TableFormat) SerDeInfo() *SerDeInfo {
 
 func (t TableFormat) SerdeLibrary() string {
 	switch t {
-	case PARQUET:
-		return "org.apache.hadoop.hive.ql.io.parquet.serde.ParquetHiveSerDe"
+	case PARQUET, DELTA_SYMLINK, HUDI_COW, HUDI_MOR:
+		return parquetSerde
+	case DELTA, TEXTFILE:
+		return lazySimpleSerde
+	case ORC:
+		return "org.apache.hadoop.hive.ql.io.orc.OrcSerde"
+	case AVRO:
+		return "org.apache.hadoop.hive.serde2.avro.AvroSerDe"
+	case CSV:
+		return openCSVSerde
+	case JSON:
+		return openxJsonSerde
 	case ICEBERG:
 		return ""
 	default:
@@ -83,10 +198,28 @@ func (t TableFormat) SerdeLibrary() string {
 
 func (t TableFormat) SerdeParameters() map[string]string {
 	switch t {
-	case PARQUET:
+	case PARQUET, DELTA, DELTA_SYMLINK, ORC:
+		return map[string]string{
+			"serialization.format": "1",
+		}
+	case HUDI_COW, HUDI_MOR:
+		return map[string]string{
+			"serialization.format": "1",
+			HudiReadOptimizedSerde: "false",
+		}
+	case CSV:
+		return map[string]string{
+			"separatorChar": ",",
+			"quoteChar":     "\"",
+			"escapeChar":    "\\",
+		}
+	case TEXTFILE:
+		// hive's own LazySimpleSerDe defaults, fields delimited by \001
 		return map[string]string{
 			"serialization.format": "1",
 		}
+	case AVRO, JSON:
+		return map[string]string{}
 	case ICEBERG:
 		return nil
 	default:
@@ -96,14 +229,32 @@ func (t TableFormat) SerdeParameters() map[string]string {
 
 func (t TableFormat) Parameters(location string) map[string]string {
 	switch t {
-	case PARQUET:
+	case PARQUET, DELTA_SYMLINK, ORC, AVRO, CSV, TEXTFILE, JSON:
 		return map[string]string{
 			"EXTERNAL": "TRUE",
 		}
+	case DELTA:
+		return map[string]string{
+			"EXTERNAL":             "TRUE",
+			SparkProviderParameter: string(DELTA),
+			"table_type":           "DELTA",
+		}
+	case HUDI_COW:
+		return map[string]string{
+			"EXTERNAL":             "TRUE",
+			SparkProviderParameter: "hudi",
+			"type":                 "cow",
+		}
+	case HUDI_MOR:
+		return map[string]string{
+			"EXTERNAL":             "TRUE",
+			SparkProviderParameter: "hudi",
+			"type":                 "mor",
+		}
 	case ICEBERG:
 		return map[string]string{
-			"metadata_location": location,
-			"table_type":        ICEBERG,
+			IcebergMetadataLocation: location,
+			"table_type":            ICEBERG,
 		}
 	default:
 		return nil
@@ -112,7 +263,7 @@ func (t TableFormat) Parameters(location string) map[string]string {
 
 func (t TableFormat) TableType() string {
 	switch t {
-	case PARQUET:
+	case PARQUET, DELTA, DELTA_SYMLINK, HUDI_COW, HUDI_MOR, ORC, AVRO, CSV, TEXTFILE, JSON:
 		return EXTERNAL_TABLE
 	case ICEBERG:
 		return ICEBERG
@@ -137,58 +288,96 @@ type DatabaseTables struct {
 }
 
 type TableInfo struct {
-	Name             string      `json:"name"`
-	Columns          []Column    `json:"columns"`
-	Partitions       []Column    `json:"partitions"`
-	MetadataLocation string      `json:"metadata_location"`
-	Format           TableFormat `json:"format"`
+	Name             string            `json:"name" yaml:"name,omitempty"`
+	Columns          []Column          `json:"columns" yaml:"columns,omitempty"`
+	Partitions       []Column          `json:"partitions" yaml:"partitions,omitempty"`
+	MetadataLocation string            `json:"metadata_location" yaml:"metadata_location,omitempty"`
+	Format           TableFormat       `json:"format" yaml:"format,omitempty"`
+	Parameters       map[string]string `json:"parameters" yaml:"parameters,omitempty"`
+	Description      string            `json:"description" yaml:"description,omitempty"`
+	Owner            string            `json:"owner" yaml:"owner,omitempty"`
+	SerdeParameters  map[string]string `json:"serde_parameters" yaml:"serde_parameters,omitempty"`
+	Storage          *Storage          `json:"storage" yaml:"storage,omitempty"`
+	Bucketing        *Bucketing        `json:"bucketing" yaml:"bucketing,omitempty"`
+	Skewed           *SkewedInfo       `json:"skewed" yaml:"skewed,omitempty"`
 }
 
-type Column struct {
-	Name string     `json:"name"`
-	Type ColumnType `json:"type"`
+type Bucketing struct {
+	Columns     []string     `json:"columns" yaml:"columns,omitempty"`
+	NumBuckets  int          `json:"num_buckets" yaml:"num_buckets,omitempty"`
+	SortColumns []SortColumn `json:"sort_columns" yaml:"sort_columns,omitempty"`
 }
 
-func MapColumnType(t string) ColumnType {
-	if strings.HasPrefix(t, "varchar") {
-		length, err := strconv.Atoi(t[8 : len(t)-1])
-		if err != nil {
-			length = 1024
-		}
-		return ColumnType{
-			SqlType: VARCHAR,
-			Length:  length,
-		}
+type SortColumn struct {
+	Name      string `json:"name" yaml:"name,omitempty"`
+	Ascending bool   `json:"ascending" yaml:"ascending,omitempty"`
+}
+
+type SkewedInfo struct {
+	Columns   []string          `json:"columns" yaml:"columns,omitempty"`
+	Values    [][]string        `json:"values" yaml:"values,omitempty"`
+	Locations map[string]string `json:"locations" yaml:"locations,omitempty"`
+}
+
+// Storage is the storage descriptor of a table whose format is UNKNOWN,
+// kept verbatim so that it can be reproduced on another metastore.
+type Storage struct {
+	InputFormat      string `json:"input_format" yaml:"input_format,omitempty"`
+	OutputFormat     string `json:"output_format" yaml:"output_format,omitempty"`
+	SerializationLib string `json:"serialization_lib" yaml:"serialization_lib,omitempty"`
+	TableType        string `json:"table_type" yaml:"table_type,omitempty"`
+}
+
+// IsHudiReadOptimized tells whether the table is the read optimized view of a
+// hudi merge-on-read table, as flagged by its serde parameter, falling back to
+// the "_ro" suffix it is registered with next to its "_rt" realtime view.
+func (t TableInfo) IsHudiReadOptimized() bool {
+	if t.Format != HUDI_MOR {
+		return false
 	}
-	return ColumnType{
-		SqlType: SqlType(t),
+	if value, ok := t.SerdeParameters[HudiReadOptimizedSerde]; ok {
+		return strings.EqualFold(value, "true")
 	}
+	return strings.HasSuffix(t.Name, HudiReadOptimizedSuffix)
 }
 
-func UnmapColumnType(t ColumnType) string {
-	if t.SqlType == VARCHAR {
-		return fmt.Sprintf("%s(%d)", VARCHAR, t.Length)
+// InputFormat is the input format of the table, the read optimized view of a
+// hudi merge-on-read table being read as copy-on-write.
+func (t TableInfo) InputFormat() string {
+	if t.Format == UNKNOWN && t.Storage != nil {
+		return t.Storage.InputFormat
+	}
+	if t.IsHudiReadOptimized() {
+		return hudiInputFormat
 	}
-	return string(t.SqlType)
+	return t.Format.InputFormat()
 }
 
-type ColumnType struct {
-	SqlType SqlType `json:"sql_type"`
-	Length  int     `json:"length"`
+func (t TableInfo) OutputFormat() string {
+	if t.Format == UNKNOWN && t.Storage != nil {
+		return t.Storage.OutputFormat
+	}
+	return t.Format.OutputFormat()
 }
 
-type SqlType string
+func (t TableInfo) TableType() string {
+	if t.Format == UNKNOWN && t.Storage != nil {
+		return t.Storage.TableType
+	}
+	return t.Format.TableType()
+}
 
-const (
-	VARCHAR   SqlType = "varchar"
-	INTEGER           = "int"
-	BIGINT            = "bigint"
-	SMALLINT          = "smallint"
-	DOUBLE            = "double"
-	DATE              = "date"
-	TIMESTAMP         = "timestamp"
-	BOOLEAN           = "boolean"
-)
+type Partition struct {
+	Values   []string `json:"values"`
+	Location string   `json:"location"`
+}
+
+type Column struct {
+	Name       string            `json:"name" yaml:"name,omitempty"`
+	Type       ColumnType        `json:"type" yaml:"type,omitempty"`
+	Comment    string            `json:"comment" yaml:"comment,omitempty"`
+	Parameters map[string]string `json:"parameters" yaml:"parameters,omitempty"`
+}
 
 func strPtr(s string) *string {
 	return &s
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"encoding/json"
 	"github.com/stretchr/testify/require"
 	"testing"
 )
@@ -75,3 +76,195 @@ func TestFromInputOutput(t *testing.T) {
 		})
 	}
 }
+
+func TestFromTable(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		location   string
+		parameters map[string]string
+		serde      *SerDeInfo
+		want       TableFormat
+	}{
+		{
+			name:       "shouldDelta",
+			input:      "org.apache.hadoop.mapred.SequenceFileInputFormat",
+			parameters: map[string]string{"spark.sql.sources.provider": "DELTA"},
+			want:       DELTA,
+		},
+		{
+			name:     "shouldDeltaSymlink",
+			input:    "org.apache.hadoop.hive.ql.io.SymlinkTextInputFormat",
+			location: "s3://bucket/table/_symlink_format_manifest/",
+			want:     DELTA_SYMLINK,
+		},
+		{
+			name:       "shouldDeltaSymlinkSparkProvider",
+			input:      "org.apache.hadoop.hive.ql.io.SymlinkTextInputFormat",
+			location:   "s3://bucket/table",
+			parameters: map[string]string{"spark.sql.sources.provider": "delta"},
+			want:       DELTA_SYMLINK,
+		},
+		{
+			name:     "shouldUnknownSymlink",
+			input:    "org.apache.hadoop.hive.ql.io.SymlinkTextInputFormat",
+			location: "s3://inventory/bucket/config/hive",
+			want:     UNKNOWN,
+		},
+		{
+			name:  "shouldHudiCow",
+			input: "org.apache.hudi.hadoop.HoodieParquetInputFormat",
+			serde: &SerDeInfo{Parameters: map[string]string{"hoodie.query.as.ro.table": "false"}},
+			want:  HUDI_COW,
+		},
+		{
+			name:  "shouldHudiMorReadOptimized",
+			input: "org.apache.hudi.hadoop.HoodieParquetInputFormat",
+			serde: &SerDeInfo{Parameters: map[string]string{"hoodie.query.as.ro.table": "true"}},
+			want:  HUDI_MOR,
+		},
+		{
+			name:  "shouldHudiMorRealtime",
+			input: "org.apache.hudi.hadoop.realtime.HoodieParquetRealtimeInputFormat",
+			want:  HUDI_MOR,
+		},
+		{
+			name:  "shouldOrc",
+			input: "org.apache.hadoop.hive.ql.io.orc.OrcInputFormat",
+			want:  ORC,
+		},
+		{
+			name:  "shouldAvro",
+			input: "org.apache.hadoop.hive.ql.io.avro.AvroContainerInputFormat",
+			want:  AVRO,
+		},
+		{
+			name:  "shouldCsv",
+			input: "org.apache.hadoop.mapred.TextInputFormat",
+			serde: &SerDeInfo{SerializationLib: "org.apache.hadoop.hive.serde2.OpenCSVSerde"},
+			want:  CSV,
+		},
+		{
+			name:  "shouldTextFile",
+			input: "org.apache.hadoop.mapred.TextInputFormat",
+			serde: &SerDeInfo{SerializationLib: "org.apache.hadoop.hive.serde2.lazy.LazySimpleSerDe"},
+			want:  TEXTFILE,
+		},
+		{
+			name:  "shouldJson",
+			input: "org.apache.hadoop.mapred.TextInputFormat",
+			serde: &SerDeInfo{SerializationLib: "org.openx.data.jsonserde.JsonSerDe"},
+			want:  JSON,
+		},
+		{
+			name:  "shouldUnknownHcatalogJson",
+			input: "org.apache.hadoop.mapred.TextInputFormat",
+			serde: &SerDeInfo{SerializationLib: "org.apache.hive.hcatalog.data.JsonSerDe"},
+			want:  UNKNOWN,
+		},
+		{
+			name:  "shouldUnknownRegexText",
+			input: "org.apache.hadoop.mapred.TextInputFormat",
+			serde: &SerDeInfo{SerializationLib: "org.apache.hadoop.hive.serde2.RegexSerDe"},
+			want:  UNKNOWN,
+		},
+		{
+			name:       "shouldIceberg",
+			parameters: map[string]string{"table_type": "ICEBERG"},
+			want:       ICEBERG,
+		},
+		{
+			name:  "shouldUnknown",
+			input: "org.apache.hadoop.hive.ql.io.RCFileInputFormat",
+			want:  UNKNOWN,
+		},
+		{
+			name:       "shouldParquet",
+			input:      "org.apache.hadoop.hive.ql.io.parquet.MapredParquetInputFormat",
+			parameters: map[string]string{"EXTERNAL": "TRUE"},
+			want:       PARQUET,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, FromTable(tt.input, tt.location, tt.parameters, tt.serde))
+		})
+	}
+}
+
+func TestTableInfo_IsHudiReadOptimized(t *testing.T) {
+	tests := []struct {
+		name  string
+		table TableInfo
+		want  bool
+	}{
+		{
+			name:  "shouldReadOptimizedSerde",
+			table: TableInfo{Name: "events", Format: HUDI_MOR, SerdeParameters: map[string]string{HudiReadOptimizedSerde: "true"}},
+			want:  true,
+		},
+		{
+			name:  "shouldNotReadOptimizedSerde",
+			table: TableInfo{Name: "events_ro", Format: HUDI_MOR, SerdeParameters: map[string]string{HudiReadOptimizedSerde: "false"}},
+			want:  false,
+		},
+		{
+			name:  "shouldReadOptimizedSuffix",
+			table: TableInfo{Name: "events_ro", Format: HUDI_MOR},
+			want:  true,
+		},
+		{
+			name:  "shouldNotReadOptimizedRealtime",
+			table: TableInfo{Name: "events_rt", Format: HUDI_MOR},
+			want:  false,
+		},
+		{
+			name:  "shouldNotReadOptimizedCow",
+			table: TableInfo{Name: "events_ro", Format: HUDI_COW, SerdeParameters: map[string]string{HudiReadOptimizedSerde: "true"}},
+			want:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.table.IsHudiReadOptimized())
+		})
+	}
+}
+
+func TestColumn_JSON(t *testing.T) {
+	column := Column{
+		Name:       "id",
+		Type:       ColumnType{SqlType: BIGINT},
+		Comment:    "primary key",
+		Parameters: map[string]string{"pii": "false"},
+	}
+	data, err := json.Marshal(column)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name":"id","type":{"sql_type":"bigint","length":0},"comment":"primary key","parameters":{"pii":"false"}}`, string(data))
+
+	var got Column
+	require.NoError(t, json.Unmarshal(data, &got))
+	require.Equal(t, column, got)
+}
+
+func TestIcebergMetadataVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		location string
+		want     int
+		wantOk   bool
+	}{
+		{name: "shouldCatalogTable", location: "s3://bucket/table/metadata/00012-8051da97-485b-4715-b22f-6302b46c752e.metadata.json", want: 12, wantOk: true},
+		{name: "shouldHadoopTable", location: "s3://bucket/table/metadata/v3.metadata.json", want: 3, wantOk: true},
+		{name: "shouldGzipCatalogTable", location: "s3://bucket/table/metadata/00012-8051da97-485b-4715-b22f-6302b46c752e.gz.metadata.json", want: 12, wantOk: true},
+		{name: "shouldGzipHadoopTable", location: "s3://bucket/table/metadata/v3.gz.metadata.json", want: 3, wantOk: true},
+		{name: "shouldTableRoot", location: "s3://bucket/table", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := IcebergMetadataVersion(tt.location)
+			require.Equal(t, tt.wantOk, ok)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}